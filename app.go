@@ -10,9 +10,10 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	texttemplate "text/template"
 	"time"
 
-	reuseport "github.com/kavu/go_reuseport"
+	"github.com/tdewolff/minify/v2"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
 )
@@ -28,10 +29,48 @@ type App struct {
 
 	template      map[string]*tmpl
 	templateFuncs []template.FuncMap
+	viewDataFuncs []ViewDataFunc
+	viewEngines   []ViewEngine
+	viewMinifier  *minify.M
+	contextFuncs  []ContextFuncMap
+	textTemplate  map[string]*texttemplate.Template
+
+	renderObservers []RenderObserver
+	slowRender      time.Duration
+
+	assets      map[string]*asset
+	assetPrefix string
+
+	basePath      string
+	mounts        []*mount
+	baseURL       string
+	trustProxy    bool
+	signatureKeys [][]byte
+
+	redirectPolicy *RedirectPolicy
+
+	sitemap *Sitemap
+	health  *Health
+
+	onStart       []func(ctx context.Context) error
+	onShutdown    []func(ctx context.Context) error
+	shuttingDown  int32
+	shutdownState shutdownState
+
+	fragmentCache     FragmentCache
+	fragmentCacheOnce sync.Once
 
 	gs           *GracefulShutdown
+	addrs        []*listenAddress
 	tcpKeepAlive time.Duration
 	reusePort    bool
+	listenerName string
+
+	upgrade       bool
+	upgradeSignal os.Signal
+	prefork       int
+
+	verifyOnStart bool
 
 	ETag bool
 	H2C  bool
@@ -62,17 +101,43 @@ func (app *App) Clone() *App {
 			ConnState:         app.srv.ConnState,
 			ErrorLog:          app.srv.ErrorLog,
 		},
-		handler:       app.handler,
-		routes:        cloneRoutes(app.routes),
-		globals:       cloneMap(&app.globals),
-		template:      cloneTmpl(app.template),
-		templateFuncs: cloneFuncMaps(app.templateFuncs),
-		tcpKeepAlive:  app.tcpKeepAlive,
-		reusePort:     app.reusePort,
-		ETag:          app.ETag,
-		H2C:           app.H2C,
+		handler:         app.handler,
+		routes:          cloneRoutes(app.routes),
+		globals:         cloneMap(&app.globals),
+		template:        cloneTmpl(app.template),
+		templateFuncs:   cloneFuncMaps(app.templateFuncs),
+		viewDataFuncs:   cloneViewDataFuncs(app.viewDataFuncs),
+		viewEngines:     cloneViewEngines(app.viewEngines),
+		viewMinifier:    app.viewMinifier,
+		renderObservers: cloneRenderObservers(app.renderObservers),
+		slowRender:      app.slowRender,
+		contextFuncs:    cloneContextFuncs(app.contextFuncs),
+		textTemplate:    cloneTextTmpl(app.textTemplate),
+		assets:          cloneAssets(app.assets),
+		assetPrefix:     app.assetPrefix,
+		basePath:        app.basePath,
+		mounts:          cloneMounts(app.mounts),
+		baseURL:         app.baseURL,
+		trustProxy:      app.trustProxy,
+		signatureKeys:   cloneSignatureKeys(app.signatureKeys),
+		redirectPolicy:  cloneRedirectPolicy(app.redirectPolicy),
+		fragmentCache:   app.fragmentCache,
+		addrs:           app.addrs,
+		tcpKeepAlive:    app.tcpKeepAlive,
+		reusePort:       app.reusePort,
+		listenerName:    app.listenerName,
+		upgrade:         app.upgrade,
+		upgradeSignal:   app.upgradeSignal,
+		prefork:         app.prefork,
+		onStart:         append([]func(ctx context.Context) error(nil), app.onStart...),
+		onShutdown:      append([]func(ctx context.Context) error(nil), app.onShutdown...),
+		verifyOnStart:   app.verifyOnStart,
+		ETag:            app.ETag,
+		H2C:             app.H2C,
 	}
 	x.srv.Handler = x
+	x.sitemap = cloneSitemap(x, app.sitemap)
+	x.health = cloneHealth(x, app.health)
 
 	if app.srv.TLSConfig != nil {
 		x.srv.TLSConfig = app.srv.TLSConfig.Clone()
@@ -88,9 +153,32 @@ func (app *App) Clone() *App {
 	return x
 }
 
-// Address sets server address
-func (app *App) Address(addr string) *App {
-	app.srv.Addr = addr
+// Address sets server listen addresses,
+// all addresses share the same server
+//
+//	app.Address(":8080")
+//	app.Address("tcp://0.0.0.0:8080", "tcp6://[::]:8080")
+//	app.Address("unix:///run/app.sock?mode=0660&owner=www-data&group=www-data")
+func (app *App) Address(addrs ...string) *App {
+	xs := make([]*listenAddress, len(addrs))
+	for i, addr := range addrs {
+		a, err := parseListenAddress(addr)
+		if err != nil {
+			panicf("invalid address '%s'; %v", addr, err)
+		}
+		xs[i] = a
+	}
+
+	app.addrs = xs
+
+	// server's addr is the first tcp address
+	app.srv.Addr = ""
+	for _, a := range xs {
+		if a.network != "unix" {
+			app.srv.Addr = a.address
+			break
+		}
+	}
 	return app
 }
 
@@ -102,21 +190,45 @@ func (app *App) Handler(h http.Handler) *App {
 
 func (app *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	app.onceServeHTTP.Do(func() {
-		app.serveHandler = app.handler
-		if app.serveHandler == nil {
-			app.serveHandler = http.DefaultServeMux
-		}
+		app.serveHandler = http.HandlerFunc(app.serve)
 
 		if app.H2C {
 			app.serveHandler = h2c.NewHandler(app.serveHandler, &http2.Server{})
 		}
 	})
 
+	app.serveHandler.ServeHTTP(w, r)
+}
+
+func (app *App) serve(w http.ResponseWriter, r *http.Request) {
+	if app.health != nil && app.health.serve(w, r) {
+		return
+	}
+
+	for _, m := range app.mounts {
+		if hasPathPrefix(r.URL.Path, m.app.basePath) {
+			m.app.serve(w, r)
+			return
+		}
+	}
+
+	r2, ok := stripBasePath(r, app.basePath)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	r = r2
+
+	h := app.handler
+	if h == nil {
+		h = http.DefaultServeMux
+	}
+
 	ctx := r.Context()
 	ctx = context.WithValue(ctx, ctxKeyApp{}, app)
 	r = r.WithContext(ctx)
 
-	app.serveHandler.ServeHTTP(w, r)
+	h.ServeHTTP(w, r)
 }
 
 // Server returns server inside app
@@ -124,25 +236,36 @@ func (app *App) Server() *http.Server {
 	return &app.srv
 }
 
-// Shutdown shutdowns server
+// Shutdown shutdowns server,
+// only first call do shutdown, other calls wait for the result
 func (app *App) Shutdown(ctx context.Context) error {
-	if app.gs != nil {
-		for _, fn := range app.gs.notiFns {
-			go fn()
-		}
+	return app.gracefulShutdown(ctx, true)
+}
 
-		if app.gs.wait > 0 {
-			time.Sleep(app.gs.wait)
+// gracefulShutdown shutdowns server,
+// notifies systemd that service is stopping if sdStop is true
+func (app *App) gracefulShutdown(ctx context.Context, sdStop bool) error {
+	return app.shutdownState.do(func() error {
+		app.setShuttingDown()
+		if sdStop {
+			sdStopping(app.gs, len(app.onShutdown) > 0)
 		}
 
-		if app.gs.timeout > 0 {
-			var cancel context.CancelFunc
-			ctx, cancel = context.WithTimeout(ctx, app.gs.timeout)
-			defer cancel()
+		var timeout time.Duration
+		if app.gs != nil {
+			for _, fn := range app.gs.notiFns {
+				go fn()
+			}
+
+			if app.gs.wait > 0 {
+				time.Sleep(app.gs.wait)
+			}
+
+			timeout = app.gs.timeout
 		}
-	}
 
-	return app.srv.Shutdown(ctx)
+		return app.shutdownServer(ctx, timeout)
+	})
 }
 
 // TCPKeepAlive sets tcp keep-alive interval when using app.ListenAndServe
@@ -157,53 +280,187 @@ func (app *App) ReusePort(enable bool) *App {
 	return app
 }
 
-func (app *App) listenAndServe() (err error) {
-	addr := app.srv.Addr
-	if addr == "" {
-		addr = ":http"
+// listen creates app's listeners,
+// uses listeners from systemd socket activation if passed,
+// max limits number of inherited listeners, max <= 0 takes all
+func (app *App) listen(max int) ([]net.Listener, error) {
+	lns, err := takeInheritedListeners(app.listenerName, max)
+	if err != nil {
+		return nil, err
+	}
+	if len(lns) == 0 {
+		lns, err = app.listenAddrs()
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	var ln net.Listener
-	if app.reusePort {
-		ln, err = reuseport.NewReusablePortListener("tcp", addr)
-	} else {
-		ln, err = net.Listen("tcp", addr)
+	for i, ln := range lns {
+		lns[i] = app.wrapListener(ln)
 	}
-	if err != nil {
-		return
+	return lns, nil
+}
+
+func (app *App) listenAddrs() ([]net.Listener, error) {
+	addrs := app.addrs
+	if len(addrs) == 0 {
+		addr := app.srv.Addr
+		if addr == "" {
+			addr = ":http"
+		}
+		addrs = []*listenAddress{{network: "tcp", address: addr}}
 	}
 
-	if d := app.tcpKeepAlive; d > 0 {
-		ln = tcpKeepAliveListener{ln.(*net.TCPListener), d}
+	lns := make([]net.Listener, 0, len(addrs))
+	for _, addr := range addrs {
+		// prefork's children listen on the same address
+		ln, err := addr.listen(app.reusePort || app.prefork > 0)
+		if err != nil {
+			for _, ln := range lns {
+				ln.Close()
+			}
+			return nil, err
+		}
+		lns = append(lns, ln)
 	}
+	return lns, nil
+}
 
-	return app.Serve(ln)
+func (app *App) wrapListener(ln net.Listener) net.Listener {
+	if d := app.tcpKeepAlive; d > 0 {
+		if tl, ok := ln.(*net.TCPListener); ok {
+			return tcpKeepAliveListener{tl, d}
+		}
+	}
+	return ln
 }
 
-// ListenAndServe starts web server
-func (app *App) ListenAndServe() error {
-	if app.gs != nil {
-		// graceful shutdown
-		errChan := make(chan error)
+// serveListeners serves all listeners,
+// returns when any listener stop
+func (app *App) serveListeners(lns []net.Listener) error {
+	if len(lns) == 1 {
+		return app.Serve(lns[0])
+	}
 
+	// http.Server.Serve may set TLSConfig while setup http2,
+	// check tls before start any listener
+	useTLS := app.srv.TLSConfig != nil
+
+	errChan := make(chan error, len(lns))
+	for _, ln := range lns {
+		ln := ln
 		go func() {
-			if err := app.listenAndServe(); err != http.ErrServerClosed {
-				errChan <- err
+			if useTLS {
+				errChan <- app.srv.ServeTLS(ln, "", "")
+				return
 			}
+			errChan <- app.srv.Serve(ln)
 		}()
+	}
 
-		stop := make(chan os.Signal, 1)
-		signal.Notify(stop, syscall.SIGTERM)
+	err := <-errChan
+	if err != http.ErrServerClosed {
+		app.srv.Close()
+	}
+	return err
+}
 
-		select {
-		case err := <-errChan:
+// ListenAndServe starts web server
+func (app *App) ListenAndServe() error {
+	if app.verifyOnStart {
+		if err := app.Verify(); err != nil {
 			return err
-		case <-stop:
-			return app.Shutdown(context.Background())
 		}
 	}
 
-	return app.listenAndServe()
+	if app.prefork > 0 && !isPreforkChild() {
+		return app.runPrefork()
+	}
+
+	lns, err := app.listen(0)
+	if err != nil {
+		return err
+	}
+
+	err = app.runStartHooks(context.Background())
+	if err != nil {
+		closeListeners(lns)
+		return err
+	}
+
+	stop := sdReady()
+	defer stop()
+	upgradeReady()
+
+	var upgraded <-chan struct{}
+	if app.upgrade {
+		keepUnixSocket(lns)
+
+		var stopUpgrade func()
+		upgraded, stopUpgrade = watchUpgrade(getUpgradeSignal(app.upgradeSignal), app.upgradeListeners(lns))
+		defer stopUpgrade()
+	}
+
+	return app.run(lns, upgraded)
+}
+
+func (app *App) upgradeListeners(lns []net.Listener) []upgradeListener {
+	xs := make([]upgradeListener, len(lns))
+	for i, ln := range lns {
+		xs[i] = upgradeListener{name: app.listenerName, ln: ln}
+	}
+	return xs
+}
+
+// run serves listeners, shutdowns server when receive terminate signal
+// if graceful shutdown enabled, or when upgraded is closed
+func (app *App) run(lns []net.Listener, upgraded <-chan struct{}) error {
+	errChan := make(chan error, 1)
+
+	go func() {
+		errChan <- app.serveListeners(lns)
+	}()
+
+	var stop chan os.Signal
+	if app.gs != nil || isPreforkChild() {
+		// graceful shutdown, prefork child always shutdown gracefully
+		// since parent forwards SIGTERM to stop children
+		stop = make(chan os.Signal, 1)
+		signal.Notify(stop, syscall.SIGTERM)
+		defer signal.Stop(stop)
+	}
+
+	select {
+	case err := <-errChan:
+		if err != http.ErrServerClosed || !app.shutdownState.isStarted() {
+			// server failed or closed directly by app.Server(),
+			// still runs shutdown hooks
+			var timeout time.Duration
+			if app.gs != nil {
+				timeout = app.gs.timeout
+			}
+			shutdownErr := app.shutdown(context.Background(), timeout)
+			if shutdownErr == nil {
+				return err
+			}
+			if err == http.ErrServerClosed {
+				return shutdownErr
+			}
+			return newErrShutdown(appendShutdownError([]error{err}, shutdownErr))
+		}
+
+		// wait for shutdown hooks
+		<-app.shutdownState.wait()
+		if app.shutdownState.err != nil || app.gs != nil {
+			return app.shutdownState.err
+		}
+		return err
+	case <-stop:
+		return app.Shutdown(context.Background())
+	case <-upgraded:
+		// new process is main process now, service is not stopping
+		return app.gracefulShutdown(context.Background(), false)
+	}
 }
 
 // Serve serves listener
@@ -3,13 +3,14 @@ package hime
 import (
 	"context"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
-	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v3"
 )
 
@@ -17,12 +18,19 @@ import (
 type Apps struct {
 	list []*App
 	gs   *GracefulShutdown
+
+	upgrade       bool
+	upgradeSignal os.Signal
+
+	shutdownState shutdownState
 }
 
 // AppsConfig is the hime multiple apps config
 type AppsConfig struct {
 	GracefulShutdown *GracefulShutdown `yaml:"gracefulShutdown" json:"gracefulShutdown"`
 	HTTPSRedirect    *HTTPSRedirect    `yaml:"httpsRedirect" json:"httpsRedirect"`
+	Upgrade          *bool             `yaml:"upgrade" json:"upgrade"`
+	UpgradeSignal    string            `yaml:"upgradeSignal" json:"upgradeSignal"`
 }
 
 // Merge merges multiple *App into *Apps
@@ -35,6 +43,12 @@ func (apps *Apps) Config(config AppsConfig) *Apps {
 	if config.GracefulShutdown != nil {
 		apps.gs = config.GracefulShutdown
 	}
+	if config.Upgrade != nil {
+		apps.upgrade = *config.Upgrade
+	}
+	if config.UpgradeSignal != "" {
+		apps.upgradeSignal = parseSignal(config.UpgradeSignal)
+	}
 
 	if rd := config.HTTPSRedirect; rd != nil {
 		go func() {
@@ -67,90 +81,243 @@ func (apps *Apps) ParseConfigFile(filename string) *Apps {
 	return apps.ParseConfig(data)
 }
 
-func (apps *Apps) listenAndServe() error {
-	eg, ctx := errgroup.WithContext(context.Background())
-
-	for _, app := range apps.list {
-		eg.Go(app.ListenAndServe)
+// listen creates listeners for all apps,
+// apps with listener name take inherited listeners first,
+// then each app without name takes one of remaining
+func (apps *Apps) listen() ([][]net.Listener, error) {
+	lns := make([][]net.Listener, len(apps.list))
+	closeAll := func() {
+		for _, xs := range lns {
+			for _, ln := range xs {
+				ln.Close()
+			}
+		}
 	}
 
-	<-ctx.Done()
-	go apps.Shutdown(context.Background())
+	for _, named := range []bool{true, false} {
+		for i, app := range apps.list {
+			if (app.listenerName != "") != named {
+				continue
+			}
 
-	return eg.Wait()
+			max := 1
+			if named {
+				max = 0
+			}
+			xs, err := app.listen(max)
+			if err != nil {
+				closeAll()
+				return nil, err
+			}
+			lns[i] = xs
+		}
+	}
+	return lns, nil
 }
 
 // ListenAndServe starts web servers
 func (apps *Apps) ListenAndServe() error {
-	if apps.gs != nil {
-		return apps.listenAndServeGracefully()
+	for _, app := range apps.list {
+		if app.verifyOnStart {
+			if err := app.Verify(); err != nil {
+				return err
+			}
+		}
 	}
 
-	return apps.listenAndServe()
-}
-
-// GracefulShutdown changes apps to graceful shutdown mode
-func (apps *Apps) GracefulShutdown() *GracefulShutdown {
-	if apps.gs == nil {
-		apps.gs = &GracefulShutdown{}
+	lns, err := apps.listen()
+	if err != nil {
+		return err
 	}
 
-	return apps.gs
-}
+	for i, app := range apps.list {
+		err = app.runStartHooks(context.Background())
+		if err != nil {
+			for _, xs := range lns {
+				closeListeners(xs)
+			}
 
-// Shutdown shutdowns all apps
-func (apps *Apps) Shutdown(ctx context.Context) error {
-	if apps.gs != nil {
-		for _, fn := range apps.gs.notiFns {
-			go fn()
-		}
-		for _, app := range apps.list {
-			if app.gs != nil {
-				for _, fn := range app.gs.notiFns {
-					go fn()
-				}
+			// runs shutdown hooks of apps that already started
+			var timeout time.Duration
+			if apps.gs != nil {
+				timeout = apps.gs.timeout
+			}
+			errs := []error{err}
+			for _, app := range apps.list[:i] {
+				errs = appendShutdownError(errs, app.shutdown(context.Background(), timeout))
 			}
+			return newErrShutdown(errs)
 		}
+	}
 
-		if apps.gs.wait > 0 {
-			time.Sleep(apps.gs.wait)
-		}
+	stop := sdReady()
+	defer stop()
+	upgradeReady()
 
-		if apps.gs.timeout > 0 {
-			var cancel context.CancelFunc
-			ctx, cancel = context.WithTimeout(ctx, apps.gs.timeout)
-			defer cancel()
+	var upgraded <-chan struct{}
+	if apps.upgrade {
+		var xs []upgradeListener
+		for i, app := range apps.list {
+			keepUnixSocket(lns[i])
+			xs = append(xs, app.upgradeListeners(lns[i])...)
 		}
-	}
 
-	eg := errgroup.Group{}
-
-	for _, app := range apps.list {
-		app := app
-		eg.Go(func() error { return app.srv.Shutdown(ctx) })
+		var stopUpgrade func()
+		upgraded, stopUpgrade = watchUpgrade(getUpgradeSignal(apps.upgradeSignal), xs)
+		defer stopUpgrade()
 	}
 
-	return eg.Wait()
-}
+	if apps.gs == nil && upgraded == nil {
+		return apps.serve(lns)
+	}
 
-// ListenAndServe starts web servers in graceful shutdown mode
-func (apps *Apps) listenAndServeGracefully() error {
-	errChan := make(chan error)
+	errChan := make(chan error, 1)
 
 	go func() {
-		err := apps.listenAndServe()
-		if err != http.ErrServerClosed {
-			errChan <- err
+		err := apps.serve(lns)
+		if err == http.ErrServerClosed && apps.gs != nil {
+			err = nil
 		}
+		errChan <- err
 	}()
 
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, syscall.SIGTERM)
+	var sig chan os.Signal
+	if apps.gs != nil {
+		// graceful shutdown
+		sig = make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGTERM)
+		defer signal.Stop(sig)
+	}
 
 	select {
 	case err := <-errChan:
 		return err
-	case <-stop:
+	case <-sig:
 		return apps.Shutdown(context.Background())
+	case <-upgraded:
+		// new process is main process now, service is not stopping
+		return apps.gracefulShutdown(context.Background(), false)
 	}
 }
+
+// serve serves all apps, shutdowns all apps when any app stopped,
+// returns errors from all apps
+func (apps *Apps) serve(lns [][]net.Listener) error {
+	type result struct {
+		app *App
+		err error
+	}
+
+	resultChan := make(chan result, len(apps.list))
+	for i, app := range apps.list {
+		app, lns := app, lns[i]
+		go func() {
+			resultChan <- result{app, app.run(lns, nil)}
+		}()
+	}
+
+	var (
+		errs   []error
+		closed bool
+	)
+	for i := range apps.list {
+		x := <-resultChan
+		if i == 0 && !x.app.isShuttingDown() {
+			go apps.Shutdown(context.Background())
+		}
+
+		if x.err == http.ErrServerClosed {
+			closed = true
+			continue
+		}
+		errs = appendShutdownError(errs, x.err)
+	}
+
+	if len(errs) == 0 && closed {
+		return http.ErrServerClosed
+	}
+	return newErrShutdown(errs)
+}
+
+// Upgrade enables zero-downtime upgrade for all apps,
+// see App.Upgrade
+func (apps *Apps) Upgrade(enable bool) *Apps {
+	apps.upgrade = enable
+	return apps
+}
+
+// UpgradeSignal sets signal that trigger upgrade
+//
+// default is SIGUSR2
+func (apps *Apps) UpgradeSignal(sig os.Signal) *Apps {
+	apps.upgradeSignal = sig
+	return apps
+}
+
+// GracefulShutdown changes apps to graceful shutdown mode
+func (apps *Apps) GracefulShutdown() *GracefulShutdown {
+	if apps.gs == nil {
+		apps.gs = &GracefulShutdown{}
+	}
+
+	return apps.gs
+}
+
+// Shutdown shutdowns all apps,
+// only first call do shutdown, other calls wait for the result
+func (apps *Apps) Shutdown(ctx context.Context) error {
+	return apps.gracefulShutdown(ctx, true)
+}
+
+// gracefulShutdown shutdowns all apps,
+// notifies systemd that service is stopping if sdStop is true
+func (apps *Apps) gracefulShutdown(ctx context.Context, sdStop bool) error {
+	return apps.shutdownState.do(func() error {
+		hooks := false
+		for _, app := range apps.list {
+			app.setShuttingDown()
+			hooks = hooks || len(app.onShutdown) > 0
+		}
+		if sdStop {
+			sdStopping(apps.gs, hooks)
+		}
+
+		var timeout time.Duration
+		if apps.gs != nil {
+			for _, fn := range apps.gs.notiFns {
+				go fn()
+			}
+			for _, app := range apps.list {
+				if app.gs != nil {
+					for _, fn := range app.gs.notiFns {
+						go fn()
+					}
+				}
+			}
+
+			if apps.gs.wait > 0 {
+				time.Sleep(apps.gs.wait)
+			}
+
+			timeout = apps.gs.timeout
+		}
+
+		var wg sync.WaitGroup
+		appErrs := make([]error, len(apps.list))
+		for i, app := range apps.list {
+			i, app := i, app
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				appErrs[i] = app.shutdown(ctx, timeout)
+			}()
+		}
+		wg.Wait()
+
+		var errs []error
+		for _, err := range appErrs {
+			errs = appendShutdownError(errs, err)
+		}
+		return newErrShutdown(errs)
+	})
+}
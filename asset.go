@@ -0,0 +1,217 @@
+package hime
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/css"
+	"github.com/tdewolff/minify/v2/js"
+)
+
+// AssetsConfig is assets config
+type AssetsConfig struct {
+	Dir     string              `yaml:"dir" json:"dir"`
+	Prefix  string              `yaml:"prefix" json:"prefix"`
+	Minify  bool                `yaml:"minify" json:"minify"`
+	Bundles map[string][]string `yaml:"bundles" json:"bundles"`
+}
+
+type asset struct {
+	contentType string
+	data        []byte
+	etag        string
+	version     string
+	modTime     time.Time
+}
+
+// Assets is asset bundle loader
+type Assets struct {
+	app      *App
+	fs       fs.FS
+	dir      string
+	minifier *minify.M
+}
+
+// Assets creates new asset bundle loader
+func (app *App) Assets() *Assets {
+	if app.assets == nil {
+		app.assets = make(map[string]*asset)
+	}
+	return &Assets{
+		app: app,
+	}
+}
+
+// Config loads assets config
+func (as *Assets) Config(cfg AssetsConfig) *Assets {
+	as.Dir(cfg.Dir)
+	if cfg.Prefix != "" {
+		as.Prefix(cfg.Prefix)
+	}
+	if cfg.Minify {
+		as.Minify()
+	}
+	for name, filenames := range cfg.Bundles {
+		as.Bundle(name, filenames...)
+	}
+
+	return as
+}
+
+// Dir sets root directory when load asset files
+//
+// default is ""
+func (as *Assets) Dir(path string) *Assets {
+	as.dir = path
+	return as
+}
+
+// FS uses fs when load asset files
+func (as *Assets) FS(fs fs.FS) *Assets {
+	as.fs = fs
+	return as
+}
+
+// Prefix sets url prefix for app's assets
+//
+// default is "/assets/"
+func (as *Assets) Prefix(prefix string) *Assets {
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	as.app.assetPrefix = prefix
+	return as
+}
+
+// Minify enables minify css and js bundles,
+// must call before Bundle
+func (as *Assets) Minify() *Assets {
+	as.minifier = minify.New()
+	as.minifier.Add("text/css", &css.Minifier{})
+	as.minifier.Add("application/javascript", js.DefaultMinifier)
+	return as
+}
+
+func (as *Assets) readFile(filename string) ([]byte, error) {
+	if as.fs == nil {
+		return ioutil.ReadFile(filename)
+	}
+	return fs.ReadFile(as.fs, filename)
+}
+
+// Bundle concatenates given files into a bundle,
+// bundle's type is detected from name's extension (.css or .js)
+func (as *Assets) Bundle(name string, filenames ...string) *Assets {
+	if _, ok := as.app.assets[name]; ok {
+		panicf("asset '%s' already exists", name)
+	}
+
+	// js files join with ";" to prevent automatic semicolon insertion
+	// merges last statement of file with next file
+	var contentType, mediaType, sep string
+	switch path.Ext(name) {
+	case ".css":
+		contentType, mediaType, sep = "text/css; charset=utf-8", "text/css", "\n"
+	case ".js":
+		contentType, mediaType, sep = "text/javascript; charset=utf-8", "application/javascript", ";\n"
+	default:
+		panicf("unknown asset type '%s'", name)
+	}
+
+	buf := getBytes()
+	defer putBytes(buf)
+
+	for _, filename := range joinTemplateDir(as.dir, filenames...) {
+		b, err := as.readFile(filename)
+		if err != nil {
+			panicf("read asset file; %v", err)
+		}
+		buf.Write(b)
+		buf.WriteString(sep)
+	}
+
+	var data []byte
+	if as.minifier != nil {
+		var err error
+		data, err = as.minifier.Bytes(mediaType, buf.Bytes())
+		if err != nil {
+			panicf("minify asset '%s'; %v", name, err)
+		}
+	} else {
+		data = make([]byte, buf.Len())
+		copy(data, buf.Bytes())
+	}
+
+	as.app.assets[name] = &asset{
+		contentType: contentType,
+		data:        data,
+		etag:        etag(data),
+		version:     assetVersion(data),
+		modTime:     time.Now(),
+	}
+
+	return as
+}
+
+func (app *App) getAssetPrefix() string {
+	if app.assetPrefix == "" {
+		return "/assets/"
+	}
+	return app.assetPrefix
+}
+
+// Asset gets asset url from given bundle name
+func (app *App) Asset(name string) string {
+	a, ok := app.assets[name]
+	if !ok {
+		panic(newErrAssetNotFound(name))
+	}
+	return app.basePath + app.getAssetPrefix() + name + "?v=" + a.version
+}
+
+// Asset gets asset url from given bundle name
+func (ctx *Context) Asset(name string) string {
+	return ctx.app.Asset(name)
+}
+
+// AssetHandler returns handler that serves app's bundles from memory,
+// handler must be registered at assets prefix
+func (app *App) AssetHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, app.getAssetPrefix())
+		a, ok := app.assets[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", a.contentType)
+		w.Header().Set("ETag", a.etag)
+		http.ServeContent(w, r, name, a.modTime, bytes.NewReader(a.data))
+	})
+}
+
+func assetVersion(b []byte) string {
+	hash := sha1.Sum(b)
+	return hex.EncodeToString(hash[:4])
+}
+
+func cloneAssets(xs map[string]*asset) map[string]*asset {
+	if xs == nil {
+		return nil
+	}
+
+	rs := make(map[string]*asset)
+	for k, v := range xs {
+		rs[k] = v
+	}
+	return rs
+}
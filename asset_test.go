@@ -0,0 +1,127 @@
+package hime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssets(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Bundle", func(t *testing.T) {
+		app := New()
+		app.Assets().
+			Dir("testdata/assets").
+			Bundle("app.css", "a.css", "b.css").
+			Bundle("app.js", "a.js", "b.js")
+
+		if assert.Contains(t, app.assets, "app.css") {
+			assert.Equal(t, "body {\n  color: red;\n}\n\n.b {\n  margin: 0px;\n}\n\n", string(app.assets["app.css"].data))
+		}
+		assert.Contains(t, app.assets, "app.js")
+	})
+
+	t.Run("Bundle js without semicolon", func(t *testing.T) {
+		app := New()
+		app.Assets().
+			Dir("testdata/assets").
+			Bundle("app.js", "c.js", "d.js")
+
+		if assert.Contains(t, app.assets, "app.js") {
+			assert.Equal(t, "var c = 1\n;\n(function () {})()\n;\n", string(app.assets["app.js"].data))
+		}
+	})
+
+	t.Run("Bundle with minify", func(t *testing.T) {
+		app := New()
+		app.Assets().
+			Dir("testdata/assets").
+			Minify().
+			Bundle("app.css", "a.css", "b.css")
+
+		if assert.Contains(t, app.assets, "app.css") {
+			assert.Equal(t, "body{color:red}.b{margin:0}", string(app.assets["app.css"].data))
+		}
+	})
+
+	t.Run("Bundle duplicate", func(t *testing.T) {
+		as := New().Assets().Dir("testdata/assets").Bundle("app.css", "a.css")
+		assert.Panics(t, func() { as.Bundle("app.css", "b.css") })
+	})
+
+	t.Run("Bundle unknown type", func(t *testing.T) {
+		as := New().Assets().Dir("testdata/assets")
+		assert.Panics(t, func() { as.Bundle("app.txt", "a.css") })
+	})
+
+	t.Run("Bundle file not exists", func(t *testing.T) {
+		as := New().Assets().Dir("testdata/assets")
+		assert.Panics(t, func() { as.Bundle("app.css", "not-exists.css") })
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		app := New().ParseConfig([]byte(`
+assets:
+  dir: testdata/assets
+  prefix: /static
+  minify: true
+  bundles:
+    app.css: [a.css, b.css]
+    app.js: [a.js, b.js]`))
+
+		assert.Equal(t, "/static/", app.assetPrefix)
+		assert.Len(t, app.assets, 2)
+	})
+
+	t.Run("Asset", func(t *testing.T) {
+		app := New()
+		app.Assets().Dir("testdata/assets").Bundle("app.css", "a.css")
+
+		assert.Equal(t, "/assets/app.css?v="+app.assets["app.css"].version, app.Asset("app.css"))
+		assert.Panics(t, func() { app.Asset("not-exists.css") })
+	})
+
+	t.Run("Asset template func", func(t *testing.T) {
+		app := New()
+		app.Assets().Dir("testdata/assets").Prefix("/static/").Bundle("app.js", "a.js")
+		app.Template().Parse("t", `<script src="{{asset "app.js"}}"></script>`)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		app.Handler(Handler(func(ctx *Context) error {
+			return ctx.View("t", nil)
+		})).ServeHTTP(w, r)
+
+		assert.Equal(t, `<script src="/static/app.js?v=`+app.assets["app.js"].version+`"></script>`, w.Body.String())
+	})
+
+	t.Run("AssetHandler", func(t *testing.T) {
+		app := New()
+		app.Assets().Dir("testdata/assets").Bundle("app.css", "a.css")
+		h := app.AssetHandler()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/assets/app.css", nil)
+		h.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/css; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "body {\n  color: red;\n}\n\n", w.Body.String())
+
+		et := w.Header().Get("ETag")
+		assert.NotEmpty(t, et)
+
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodGet, "/assets/app.css", nil)
+		r.Header.Set("If-None-Match", et)
+		h.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodGet, "/assets/not-exists.css", nil)
+		h.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
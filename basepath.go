@@ -0,0 +1,103 @@
+package hime
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type mount struct {
+	prefix string
+	app    *App
+}
+
+// BasePath sets path prefix that app serves under (e.g. /admin),
+// route, redirect and asset urls are prefixed with base path,
+// and base path is stripped from incoming request's path
+func (app *App) BasePath(p string) *App {
+	p = strings.Trim(p, "/")
+	if p != "" {
+		p = "/" + p
+	}
+	app.basePath = p
+
+	// update mounted apps
+	for _, m := range app.mounts {
+		m.app.BasePath(app.basePath + m.prefix)
+	}
+	return app
+}
+
+// Mount mounts sub app at prefix,
+// sub app keeps its own templates, globals and handler,
+// and serves requests under prefix with base path app's base path + prefix
+func (app *App) Mount(prefix string, sub *App) *App {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		panicf("mount prefix required")
+	}
+	prefix = "/" + prefix
+
+	app.mounts = append(app.mounts, &mount{prefix: prefix, app: sub})
+	sort.SliceStable(app.mounts, func(i, j int) bool {
+		return len(app.mounts[i].prefix) > len(app.mounts[j].prefix)
+	})
+	sub.BasePath(app.basePath + prefix)
+	return app
+}
+
+// withBasePath prefixes root-relative path with app's base path,
+// absolute url is returned as is
+func (app *App) withBasePath(p string) string {
+	if app.basePath == "" || !strings.HasPrefix(p, "/") || strings.HasPrefix(p, "//") {
+		return p
+	}
+	return app.basePath + p
+}
+
+// hasPathPrefix reports whether p is prefix or under prefix
+func hasPathPrefix(p, prefix string) bool {
+	if !strings.HasPrefix(p, prefix) {
+		return false
+	}
+	if len(p) == len(prefix) {
+		return true
+	}
+	switch p[len(prefix)] {
+	case '/', '?', '#':
+		return true
+	}
+	return false
+}
+
+// stripBasePath returns shallow copy of r with base path stripped from url's path
+func stripBasePath(r *http.Request, basePath string) (*http.Request, bool) {
+	if basePath == "" {
+		return r, true
+	}
+	if r.URL.Path != basePath && !strings.HasPrefix(r.URL.Path, basePath+"/") {
+		return nil, false
+	}
+
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, basePath), "/")
+	r2.URL.RawPath = ""
+	if strings.HasPrefix(r.URL.RawPath, basePath) {
+		r2.URL.RawPath = "/" + strings.TrimPrefix(strings.TrimPrefix(r.URL.RawPath, basePath), "/")
+	}
+	return r2, true
+}
+
+func cloneMounts(xs []*mount) []*mount {
+	if xs == nil {
+		return nil
+	}
+
+	rs := make([]*mount, len(xs))
+	copy(rs, xs)
+	return rs
+}
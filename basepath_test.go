@@ -0,0 +1,184 @@
+package hime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasePath(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Normalize", func(t *testing.T) {
+		assert.Equal(t, "/admin", New().BasePath("admin/").basePath)
+		assert.Equal(t, "/admin", New().BasePath("/admin").basePath)
+		assert.Equal(t, "", New().BasePath("/").basePath)
+	})
+
+	t.Run("Route", func(t *testing.T) {
+		app := New().BasePath("/admin").Routes(Routes{"index": "/", "user": "/users/{id}"})
+		assert.Equal(t, "/admin/", app.Route("index"))
+		assert.Equal(t, "/admin/users/1?a=b", app.Route("user", 1, &Param{"a", "b"}))
+		assert.Equal(t, "/users/{id}", app.RoutePattern("user"))
+
+		app.BaseURL("https://example.com")
+		assert.Equal(t, "https://example.com/admin/users/1", app.RouteURL("user", 1))
+	})
+
+	t.Run("Route path like base path", func(t *testing.T) {
+		app := New().BasePath("/admin").Routes(Routes{"s": "/admin/settings"})
+		assert.Equal(t, "/admin/admin/settings", app.Route("s"))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/admin/", nil)
+		ctx := NewAppContext(app, w, r)
+		assert.NoError(t, ctx.RedirectTo("s"))
+		assert.Equal(t, "/admin/admin/settings", w.Header().Get("Location"))
+	})
+
+	t.Run("Route template func", func(t *testing.T) {
+		app := New().BasePath("/admin").Routes(Routes{"user": "/users/{id}"})
+		app.Template().Parse("t", `{{route "user" 1}}`)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		assert.NoError(t, NewAppContext(app, w, r).View("t", nil))
+		assert.Equal(t, "/admin/users/1", w.Body.String())
+	})
+
+	t.Run("Asset", func(t *testing.T) {
+		app := New().BasePath("/admin")
+		app.Assets().Dir("testdata/assets").Bundle("app.css", "a.css")
+		assert.True(t, strings.HasPrefix(app.Asset("app.css"), "/admin/assets/app.css?v="))
+	})
+
+	redirectCases := []struct {
+		Name   string
+		Do     func(ctx *Context) error
+		Output string
+	}{
+		{"Redirect", func(ctx *Context) error { return ctx.Redirect("/signin") }, "/admin/signin"},
+		{"Redirect path like base path", func(ctx *Context) error { return ctx.Redirect("/admin/signin") }, "/admin/admin/signin"},
+		{"Redirect absolute", func(ctx *Context) error { return ctx.Redirect("https://example.com/signin") }, "https://example.com/signin"},
+		{"Redirect not under base path", func(ctx *Context) error { return ctx.Redirect("/administrator") }, "/admin/administrator"},
+		{"SafeRedirect", func(ctx *Context) error { return ctx.SafeRedirect("https://evil.com/signin") }, "/admin/signin"},
+		{"RedirectTo", func(ctx *Context) error { return ctx.RedirectTo("user", 1) }, "/admin/users/1"},
+		{"RedirectToGet", func(ctx *Context) error { return ctx.RedirectToGet() }, "/admin/users/1?x=1"},
+		{"RedirectBack fallback", func(ctx *Context) error { return ctx.RedirectBack("/") }, "/admin/"},
+		{"RedirectBack referer", func(ctx *Context) error {
+			ctx.Request.Header.Set("Referer", "/admin/users")
+			return ctx.RedirectBack("/")
+		}, "/admin/users"},
+		{"RedirectBackToGet", func(ctx *Context) error { return ctx.RedirectBackToGet() }, "/admin/users/1?x=1"},
+		{"SafeRedirect relative", func(ctx *Context) error { return ctx.SafeRedirect("/signin") }, "/admin/signin"},
+		{"SafeRedirectBack referer", func(ctx *Context) error {
+			ctx.Request.Header.Set("Referer", "https://example.com/admin/users")
+			return ctx.SafeRedirectBack("/")
+		}, "/admin/users"},
+		{"SafeRedirectBack fallback", func(ctx *Context) error { return ctx.SafeRedirectBack("/") }, "/admin/"},
+		{"SafeRedirectBack request uri", func(ctx *Context) error { return ctx.SafeRedirectBack("") }, "/admin/users/1?x=1"},
+	}
+
+	for _, c := range redirectCases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			app := New().BasePath("/admin").Routes(Routes{"user": "/users/{id}"})
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/admin/users/1?x=1", nil)
+			assert.NoError(t, c.Do(NewAppContext(app, w, r)))
+			assert.Equal(t, c.Output, w.Header().Get("Location"))
+		})
+	}
+
+	t.Run("Strip request path", func(t *testing.T) {
+		var paths []string
+		app := New().BasePath("/admin").Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+		}))
+
+		for _, p := range []string{"/admin", "/admin/", "/admin/users/1"} {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, p, nil))
+			assert.Equal(t, http.StatusOK, w.Code)
+		}
+		assert.Equal(t, []string{"/", "/", "/users/1"}, paths)
+
+		for _, p := range []string{"/", "/administrator", "/users/1"} {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, p, nil))
+			assert.Equal(t, http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("Signed route", func(t *testing.T) {
+		app := New().BasePath("/admin").Routes(Routes{"download": "/files/{id}"}).SignatureKeys([]byte("secret"))
+		p := app.SignedRoute("download", time.Hour, 1)
+		assert.True(t, strings.HasPrefix(p, "/admin/files/1?"))
+
+		var err error
+		app.Handler(Handler(func(ctx *Context) error {
+			err = ctx.VerifySignature()
+			return nil
+		}))
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, p, nil))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		app := New().ParseConfig([]byte(`basePath: /admin`))
+		assert.Equal(t, "/admin", app.basePath)
+		assert.Equal(t, "/admin", app.Clone().basePath)
+	})
+}
+
+func TestMount(t *testing.T) {
+	t.Parallel()
+
+	newApp := func(name string) *App {
+		app := New().Routes(Routes{"index": "/"}).Globals(Globals{"name": name})
+		app.Template().Parse("index", `{{global "name"}} {{route "index"}} {{templateName}}`)
+		app.Handler(Handler(func(ctx *Context) error {
+			return ctx.View("index", nil)
+		}))
+		return app
+	}
+
+	serve := func(app *App, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	t.Run("Serve", func(t *testing.T) {
+		admin := newApp("admin")
+		api := newApp("api")
+		api.Mount("/v1", newApp("v1"))
+		app := newApp("main").Mount("/admin", admin).Mount("api", api)
+
+		assert.Equal(t, "main / index", serve(app, "/").Body.String())
+		assert.Equal(t, "main / index", serve(app, "/administrator").Body.String())
+		assert.Equal(t, "admin /admin/ index", serve(app, "/admin").Body.String())
+		assert.Equal(t, "admin /admin/ index", serve(app, "/admin/users").Body.String())
+		assert.Equal(t, "api /api/ index", serve(app, "/api/x").Body.String())
+		assert.Equal(t, "v1 /api/v1/ index", serve(app, "/api/v1/x").Body.String())
+	})
+
+	t.Run("Base path", func(t *testing.T) {
+		admin := newApp("admin")
+		app := newApp("main").Mount("/admin", admin).BasePath("/app")
+		assert.Equal(t, "/app/admin", admin.basePath)
+
+		assert.Equal(t, "main /app/ index", serve(app, "/app/").Body.String())
+		assert.Equal(t, "admin /app/admin/ index", serve(app, "/app/admin/").Body.String())
+		assert.Equal(t, http.StatusNotFound, serve(app, "/admin/").Code)
+	})
+
+	t.Run("Empty prefix", func(t *testing.T) {
+		assert.Panics(t, func() { New().Mount("/", New()) })
+	})
+}
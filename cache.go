@@ -0,0 +1,165 @@
+package hime
+
+import (
+	"container/list"
+	"fmt"
+	"html/template"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FragmentCache is the storage for rendered template fragments
+type FragmentCache interface {
+	Get(key string) (template.HTML, bool)
+	Set(key string, value template.HTML, ttl time.Duration)
+}
+
+// defaultFragmentCacheSize is the size of app's default fragment cache
+const defaultFragmentCacheSize = 1000
+
+// FragmentCache sets fragment cache storage for template's cache func
+//
+// default is in-memory lru cache,
+// keys are stored as "<page template>:<key>",
+// fragment that varies by locale or user must include them in key
+//
+//	{{cache (cacheKey "sidebar" .Locale) "10m" "sidebar" .}}
+func (app *App) FragmentCache(c FragmentCache) *App {
+	app.fragmentCache = c
+	return app
+}
+
+func (app *App) getFragmentCache() FragmentCache {
+	app.fragmentCacheOnce.Do(func() {
+		if app.fragmentCache == nil {
+			app.fragmentCache = NewLRUFragmentCache(defaultFragmentCacheSize)
+		}
+	})
+	return app.fragmentCache
+}
+
+type lruItem struct {
+	key     string
+	value   template.HTML
+	expires time.Time
+}
+
+// LRUFragmentCache is the in-memory lru fragment cache
+type LRUFragmentCache struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+// NewLRUFragmentCache creates new lru fragment cache that holds at most size items
+func NewLRUFragmentCache(size int) *LRUFragmentCache {
+	return &LRUFragmentCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Get implements FragmentCache
+func (c *LRUFragmentCache) Get(key string) (template.HTML, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+
+	it := e.Value.(*lruItem)
+	if !it.expires.IsZero() && time.Now().After(it.expires) {
+		c.ll.Remove(e)
+		delete(c.items, key)
+		return "", false
+	}
+
+	c.ll.MoveToFront(e)
+	return it.value, true
+}
+
+// Set implements FragmentCache
+func (c *LRUFragmentCache) Set(key string, value template.HTML, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+
+	if e, ok := c.items[key]; ok {
+		it := e.Value.(*lruItem)
+		it.value = value
+		it.expires = expires
+		c.ll.MoveToFront(e)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&lruItem{key: key, value: value, expires: expires})
+
+	for c.size > 0 && c.ll.Len() > c.size {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		delete(c.items, e.Value.(*lruItem).key)
+	}
+}
+
+// Len returns number of items in cache
+func (c *LRUFragmentCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func parseCacheTTL(ttl interface{}) (time.Duration, error) {
+	switch v := ttl.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		return time.ParseDuration(v)
+	default:
+		return 0, fmt.Errorf("hime: invalid cache ttl type %T", ttl)
+	}
+}
+
+// renderCachedFragment renders named template in t's set,
+// or returns cached fragment if exists,
+// key is prefixed with page template name so pages do not share fragments
+func renderCachedFragment(c FragmentCache, t *template.Template, page string, key string, ttl interface{}, name string, data []interface{}) (template.HTML, error) {
+	d, err := parseCacheTTL(ttl)
+	if err != nil {
+		return "", err
+	}
+
+	key = page + ":" + key
+
+	if s, ok := c.Get(key); ok {
+		return s, nil
+	}
+
+	s, err := executeHTML(t, name, data)
+	if err != nil {
+		return "", err
+	}
+	c.Set(key, s, d)
+	return s, nil
+}
+
+func tfCache(key string, ttl interface{}, name string, data ...interface{}) (template.HTML, error) {
+	return "", fmt.Errorf("hime: cache '%s' can not use outside page template", key)
+}
+
+// tfCacheKey joins parts with ":" into cache key
+func tfCacheKey(parts ...interface{}) string {
+	xs := make([]string, len(parts))
+	for i, p := range parts {
+		xs[i] = fmt.Sprint(p)
+	}
+	return strings.Join(xs, ":")
+}
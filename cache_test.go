@@ -0,0 +1,115 @@
+package hime
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFragmentCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("LRU", func(t *testing.T) {
+		c := NewLRUFragmentCache(2)
+
+		_, ok := c.Get("a")
+		assert.False(t, ok)
+
+		c.Set("a", "1", 0)
+		c.Set("b", "2", 0)
+		v, ok := c.Get("a")
+		assert.True(t, ok)
+		assert.EqualValues(t, "1", v)
+
+		c.Set("c", "3", 0)
+		assert.Equal(t, 2, c.Len())
+		_, ok = c.Get("b")
+		assert.False(t, ok, "least recently used item must be evicted")
+		_, ok = c.Get("a")
+		assert.True(t, ok)
+
+		c.Set("a", "4", 0)
+		v, _ = c.Get("a")
+		assert.EqualValues(t, "4", v)
+	})
+
+	t.Run("LRU expires", func(t *testing.T) {
+		c := NewLRUFragmentCache(10)
+		c.Set("a", "1", time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+
+		_, ok := c.Get("a")
+		assert.False(t, ok)
+		assert.Equal(t, 0, c.Len())
+	})
+
+	t.Run("cache func", func(t *testing.T) {
+		app := New()
+		calls := 0
+		tp := app.Template()
+		tp.Func("count", func() int { calls++; return calls })
+		tp.Parse("t", `{{define "sidebar"}}<p>{{.}} {{count}}</p>{{end}}{{cache (cacheKey "sidebar" .) "1m" "sidebar" .}}`)
+
+		for i := 0; i < 3; i++ {
+			b := bytes.Buffer{}
+			if assert.NoError(t, app.template["t"].Execute(&b, "th")) {
+				assert.Equal(t, "<p>th 1</p>", b.String())
+			}
+		}
+
+		b := bytes.Buffer{}
+		if assert.NoError(t, app.template["t"].Execute(&b, "en")) {
+			assert.Equal(t, "<p>en 2</p>", b.String())
+		}
+	})
+
+	t.Run("cache func with custom store", func(t *testing.T) {
+		c := NewLRUFragmentCache(10)
+		app := New()
+		app.FragmentCache(c)
+		app.Template().Parse("t", `{{define "x"}}x{{end}}{{cache "k" "1m" "x"}}`)
+
+		b := bytes.Buffer{}
+		assert.NoError(t, app.template["t"].Execute(&b, nil))
+		v, ok := c.Get("t:k")
+		assert.True(t, ok)
+		assert.EqualValues(t, "x", v)
+	})
+
+	t.Run("cache func key per template", func(t *testing.T) {
+		app := New()
+		app.Template().Parse("t1", `{{define "x"}}t1{{end}}{{cache "sidebar" "1m" "x"}}`)
+		app.Template().Parse("t2", `{{define "x"}}t2{{end}}{{cache "sidebar" "1m" "x"}}`)
+
+		b := bytes.Buffer{}
+		if assert.NoError(t, app.template["t1"].Execute(&b, nil)) {
+			assert.Equal(t, "t1", b.String())
+		}
+		b.Reset()
+		if assert.NoError(t, app.template["t2"].Execute(&b, nil)) {
+			assert.Equal(t, "t2", b.String())
+		}
+	})
+
+	t.Run("cache func invalid ttl", func(t *testing.T) {
+		app := New()
+		app.Template().Parse("t", `{{define "x"}}x{{end}}{{cache "k" 1 "x"}}`)
+
+		b := bytes.Buffer{}
+		assert.Error(t, app.template["t"].Execute(&b, nil))
+	})
+
+	t.Run("cache func template not exists", func(t *testing.T) {
+		app := New()
+		app.Template().Parse("t", `{{cache "k" "1m" "x"}}`)
+
+		b := bytes.Buffer{}
+		assert.Error(t, app.template["t"].Execute(&b, nil))
+	})
+
+	t.Run("cacheKey", func(t *testing.T) {
+		assert.Equal(t, "menu:th:1", tfCacheKey("menu", "th", 1))
+	})
+}
@@ -9,17 +9,33 @@ import (
 
 // AppConfig is hime app's config
 type AppConfig struct {
-	Globals   Globals          `yaml:"globals" json:"globals"`
-	Routes    Routes           `yaml:"routes" json:"routes"`
-	Templates []TemplateConfig `yaml:"templates" json:"templates"`
-	Server    struct {
+	Globals        Globals                 `yaml:"globals" json:"globals"`
+	Routes         Routes                  `yaml:"routes" json:"routes"`
+	Templates      []TemplateConfig        `yaml:"templates" json:"templates"`
+	TextTemplates  []TextTemplateConfig    `yaml:"textTemplates" json:"textTemplates"`
+	Assets         *AssetsConfig           `yaml:"assets" json:"assets"`
+	Verify         *bool                   `yaml:"verify" json:"verify"`
+	SlowRender     string                  `yaml:"slowRender" json:"slowRender"`
+	BasePath       string                  `yaml:"basePath" json:"basePath"`
+	BaseURL        string                  `yaml:"baseURL" json:"baseURL"`
+	SignatureKeys  []string                `yaml:"signatureKeys" json:"signatureKeys"`
+	RedirectPolicy *RedirectPolicy         `yaml:"redirectPolicy" json:"redirectPolicy"`
+	Sitemap        map[string]SitemapRoute `yaml:"sitemap" json:"sitemap"`
+	Health         *HealthConfig           `yaml:"health" json:"health"`
+	Server         struct {
 		Addr              string            `yaml:"addr" json:"addr"`
+		Addrs             Addresses         `yaml:"addrs" json:"addrs"`
 		ReadTimeout       string            `yaml:"readTimeout" json:"readTimeout"`
 		ReadHeaderTimeout string            `yaml:"readHeaderTimeout" json:"readHeaderTimeout"`
 		WriteTimeout      string            `yaml:"writeTimeout" json:"writeTimeout"`
 		IdleTimeout       string            `yaml:"idleTimeout" json:"idleTimeout"`
 		ReusePort         *bool             `yaml:"reusePort" json:"reusePort"`
 		TCPKeepAlive      string            `yaml:"tcpKeepAlive" json:"tcpKeepAlive"`
+		ListenerName      string            `yaml:"listenerName" json:"listenerName"`
+		Upgrade           *bool             `yaml:"upgrade" json:"upgrade"`
+		UpgradeSignal     string            `yaml:"upgradeSignal" json:"upgradeSignal"`
+		Prefork           *int              `yaml:"prefork" json:"prefork"`
+		TrustProxy        *bool             `yaml:"trustProxy" json:"trustProxy"`
 		ETag              *bool             `yaml:"eTag" json:"eTag"`
 		H2C               *bool             `yaml:"h2c" json:"h2c"`
 		GracefulShutdown  *GracefulShutdown `yaml:"gracefulShutdown" json:"gracefulShutdown"`
@@ -54,6 +70,7 @@ func parseDuration(s string, t *time.Duration) {
 //   root: layout
 //   delims: ["{{", "}}"]
 //   minify: true
+//   stdFuncs: true
 //   preload:
 //   - comp/comp1.tmpl
 //   - comp/comp2.tmpl
@@ -62,13 +79,45 @@ func parseDuration(s string, t *time.Duration) {
 //     - main.tmpl
 //     - _layout.tmpl
 //     about.tmpl: [about.tmpl, _layout.tmpl]
+// textTemplates:
+// - dir: email
+//   list:
+//     welcome.txt: [welcome.txt]
+// assets:
+//   dir: assets
+//   prefix: /assets/
+//   minify: true
+//   bundles:
+//     app.css: [reset.css, main.css]
+//     app.js: [main.js]
+// verify: true
+// slowRender: 200ms
+// basePath: /admin
+// baseURL: https://example.com
+// signatureKeys: [new-secret, old-secret]
+// redirectPolicy:
+//   hosts: [sso.example.com, "*.example.com"]
+//   schemes: [https]
+//   validateBack: true
+// sitemap:
+//   index: {changefreq: daily, priority: 1.0}
+//   about: {changefreq: monthly}
+// health:
+//   liveness: /healthz
+//   readiness: /readyz
 // server:
+//   addrs: [tcp://0.0.0.0:8080, "tcp6://[::]:8080"]
+//   listenerName: web
+//   upgrade: true
+//   upgradeSignal: SIGUSR2
+//   prefork: 4
 //   readTimeout: 10s
 //   readHeaderTimeout: 5s
 //   writeTimeout: 5s
 //   idleTimeout: 30s
 //   eTag: true
 //   h2c: true
+//   trustProxy: true
 //   gracefulShutdown:
 //     timeout: 1m
 //     wait: 5s
@@ -76,16 +125,55 @@ func (app *App) Config(config AppConfig) *App {
 	app.Globals(config.Globals)
 	app.Routes(config.Routes)
 
+	if config.Assets != nil {
+		app.Assets().Config(*config.Assets)
+	}
+
 	for _, cfg := range config.Templates {
 		app.Template().Config(cfg)
 	}
 
+	for _, cfg := range config.TextTemplates {
+		app.TextTemplate().Config(cfg)
+	}
+
+	if config.Verify != nil {
+		app.verifyOnStart = *config.Verify
+	}
+	parseDuration(config.SlowRender, &app.slowRender)
+	if config.BasePath != "" {
+		app.BasePath(config.BasePath)
+	}
+	if config.BaseURL != "" {
+		app.BaseURL(config.BaseURL)
+	}
+	if len(config.Sitemap) > 0 {
+		app.Sitemap().Config(config.Sitemap)
+	}
+	if config.Health != nil {
+		app.Health().Config(*config.Health)
+	}
+	if config.RedirectPolicy != nil {
+		app.redirectPolicy = config.RedirectPolicy
+	}
+	if len(config.SignatureKeys) > 0 {
+		keys := make([][]byte, len(config.SignatureKeys))
+		for i, k := range config.SignatureKeys {
+			keys[i] = []byte(k)
+		}
+		app.SignatureKeys(keys...)
+	}
+
 	{
 		// server config
 		server := config.Server
 
+		addrs := server.Addrs
 		if server.Addr != "" {
-			app.srv.Addr = server.Addr
+			addrs = append(Addresses{server.Addr}, addrs...)
+		}
+		if len(addrs) > 0 {
+			app.Address(addrs...)
 		}
 		parseDuration(server.ReadTimeout, &app.srv.ReadTimeout)
 		parseDuration(server.ReadHeaderTimeout, &app.srv.ReadHeaderTimeout)
@@ -96,12 +184,27 @@ func (app *App) Config(config AppConfig) *App {
 		if server.ReusePort != nil {
 			app.reusePort = *server.ReusePort
 		}
+		if server.ListenerName != "" {
+			app.listenerName = server.ListenerName
+		}
+		if server.Upgrade != nil {
+			app.upgrade = *server.Upgrade
+		}
+		if server.UpgradeSignal != "" {
+			app.upgradeSignal = parseSignal(server.UpgradeSignal)
+		}
+		if server.Prefork != nil {
+			app.prefork = *server.Prefork
+		}
 		if server.ETag != nil {
 			app.ETag = *server.ETag
 		}
 		if server.H2C != nil {
 			app.H2C = *server.H2C
 		}
+		if server.TrustProxy != nil {
+			app.trustProxy = *server.TrustProxy
+		}
 
 		if t := server.TLS; t != nil {
 			app.srv.TLSConfig = server.TLS.config()
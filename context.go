@@ -135,40 +135,62 @@ func (ctx *Context) Handle(h http.Handler) error {
 	return nil
 }
 
-// Redirect redirects to given url
+// Redirect redirects to given url,
+// root-relative url is prefixed with app's base path
 func (ctx *Context) Redirect(url string, params ...interface{}) error {
-	p := buildPath(url, params...)
-	http.Redirect(ctx.w, ctx.Request, p, ctx.statusCodeRedirect())
+	return ctx.redirect(ctx.app.withBasePath(buildPath(url, params...)))
+}
+
+// redirect redirects to url without prefix base path,
+// url must be full request path (e.g. from route or request uri)
+func (ctx *Context) redirect(url string) error {
+	http.Redirect(ctx.w, ctx.Request, url, ctx.statusCodeRedirect())
 	return nil
 }
 
-// SafeRedirect extracts only path from url then redirect
+// SafeRedirect extracts only path from url then redirect,
+// absolute url that allowed by app's redirect policy is kept
 func (ctx *Context) SafeRedirect(url string, params ...interface{}) error {
-	p := buildPath(url, params...)
-	return ctx.Redirect(SafeRedirectPath(p))
+	return ctx.safeRedirect(buildPath(url, params...), true)
+}
+
+// safeRedirect extracts only path from url then redirect,
+// prefixes base path if url is relative to app
+func (ctx *Context) safeRedirect(url string, appRelative bool) error {
+	if ctx.app.redirectPolicy.allowedAbsolute(url) {
+		return ctx.redirect(url)
+	}
+	p := SafeRedirectPath(url)
+	if appRelative {
+		p = ctx.app.withBasePath(p)
+	}
+	return ctx.redirect(p)
 }
 
 // RedirectTo redirects to route name
 func (ctx *Context) RedirectTo(name string, params ...interface{}) error {
-	p := buildPath(ctx.app.Route(name), params...)
-	return ctx.Redirect(p)
+	return ctx.redirect(ctx.app.Route(name, params...))
 }
 
 // RedirectToGet redirects to same url back to Get
 func (ctx *Context) RedirectToGet() error {
-	return ctx.Redirect(ctx.RequestURI)
+	return ctx.redirect(ctx.RequestURI)
 }
 
-// RedirectBack redirects to referer or fallback if referer not exists
+// RedirectBack redirects to referer or fallback if referer not exists,
+// or referer not allowed when app's redirect policy validates back
 func (ctx *Context) RedirectBack(fallback string) error {
 	u := ctx.Referer()
-	if u == "" {
-		u = fallback
+	if u != "" && ctx.app.redirectPolicy != nil && ctx.app.redirectPolicy.validateBack && !ctx.allowedReferer(u) {
+		u = ""
+	}
+	if u != "" {
+		return ctx.redirect(u)
 	}
-	if u == "" {
-		u = ctx.RequestURI
+	if fallback != "" {
+		return ctx.Redirect(fallback)
 	}
-	return ctx.Redirect(u)
+	return ctx.redirect(ctx.RequestURI)
 }
 
 // RedirectBackToGet redirects to referer or fallback with same url
@@ -178,14 +200,13 @@ func (ctx *Context) RedirectBackToGet() error {
 
 // SafeRedirectBack safe redirects to referer
 func (ctx *Context) SafeRedirectBack(fallback string) error {
-	u := ctx.Request.Referer()
-	if u == "" {
-		u = fallback
+	if u := ctx.Request.Referer(); u != "" {
+		return ctx.safeRedirect(u, false)
 	}
-	if u == "" {
-		u = ctx.RequestURI
+	if fallback != "" {
+		return ctx.safeRedirect(fallback, true)
 	}
-	return ctx.SafeRedirect(u)
+	return ctx.safeRedirect(ctx.RequestURI, false)
 }
 
 // Error calls http.Error
@@ -220,17 +241,17 @@ func (ctx *Context) setETag(b []byte) bool {
 	return false
 }
 
-// View renders view
+// View renders view, returns ErrTemplateNotFound if no engine has the view
 func (ctx *Context) View(name string, data interface{}) error {
-	t, ok := ctx.app.template[name]
-	if !ok {
-		panic(newErrTemplateNotFound(name))
+	e := ctx.app.lookupView(name)
+	if e == nil {
+		return newErrTemplateNotFound(name)
 	}
 
 	buf := getBytes()
 	defer putBytes(buf)
 
-	err := t.Execute(buf, data)
+	err := ctx.app.executeView(ctx, e, buf, name, ctx.app.viewData(ctx, data))
 	if err != nil {
 		return err
 	}
@@ -3,10 +3,14 @@ package hime_test
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"html/template"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -433,6 +437,19 @@ func TestContext(t *testing.T) {
 		assert.Equal(t, w.Header().Get("Location"), "/route/1")
 	})
 
+	t.Run("RedirectTo to valid route with named param", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		app := hime.New()
+		app.Routes(hime.Routes{"user": "/users/{id}"})
+		ctx := hime.NewAppContext(app, w, r)
+
+		assert.NoError(t, ctx.RedirectTo("user", hime.Param{Name: "id", Value: 1}, ctx.Param("tab", "posts")))
+		assert.Equal(t, w.Code, http.StatusFound)
+		assert.Equal(t, w.Header().Get("Location"), "/users/1?tab=posts")
+	})
+
 	t.Run("RedirectTo to invalid route", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -651,7 +668,7 @@ func TestContext(t *testing.T) {
 		app := hime.New()
 		ctx := hime.NewAppContext(app, w, r)
 
-		assert.Panics(t, func() { ctx.View("invalid", nil) })
+		assert.IsType(t, &hime.ErrTemplateNotFound{}, ctx.View("invalid", nil))
 	})
 
 	t.Run("View with valid template", func(t *testing.T) {
@@ -738,6 +755,172 @@ func TestContext(t *testing.T) {
 		assert.Error(t, ctx.View("index", nil))
 	})
 
+	t.Run("View with view data", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/hello", nil)
+
+		app := hime.New()
+		app.ViewData(hime.WithContextData)
+		app.Template().Parse("index", `{{.Ctx.URL.Path}} {{.Data}}`)
+		ctx := hime.NewAppContext(app, w, r)
+
+		assert.NoError(t, ctx.View("index", "hime"))
+		assert.Equal(t, w.Body.String(), "/hello hime")
+	})
+
+	t.Run("View with multiple view data funcs", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		app := hime.New()
+		app.ViewData(
+			func(ctx *hime.Context, data interface{}) interface{} {
+				return map[string]interface{}{"User": "u1", "Data": data}
+			},
+			func(ctx *hime.Context, data interface{}) interface{} {
+				data.(map[string]interface{})["RequestID"] = "r1"
+				return data
+			},
+		)
+		app.Template().Parse("index", `{{.User}} {{.RequestID}} {{.Data}}`)
+		ctx := hime.NewAppContext(app, w, r)
+
+		assert.NoError(t, ctx.View("index", "hime"))
+		assert.Equal(t, w.Body.String(), "u1 r1 hime")
+	})
+
+	t.Run("View with context funcs", func(t *testing.T) {
+		app := hime.New()
+		app.ContextFuncs(hime.ContextFuncMap{
+			"path": func(ctx *hime.Context) interface{} {
+				// read ctx eagerly, factory must not call while load template
+				p := ctx.URL.Path
+				return func() string { return p }
+			},
+		})
+		app.Template().Parse("index", `{{define "p"}}[{{path}}]{{end}}{{path}} {{slot "p"}} {{templateName}}`)
+
+		for _, p := range []string{"/a", "/b", "/c"} {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, p, nil)
+			ctx := hime.NewAppContext(app, w, r)
+
+			assert.NoError(t, ctx.View("index", nil))
+			assert.Equal(t, w.Body.String(), p+" ["+p+"] index")
+		}
+	})
+
+	t.Run("View with context funcs in component", func(t *testing.T) {
+		app := hime.New()
+		app.ContextFunc("path", func(ctx *hime.Context) interface{} {
+			return func() string { return ctx.URL.Path }
+		})
+		tp := app.Template()
+		tp.Parse("index", `{{component "c"}}`)
+		tp.Component(template.Must(template.New("c").Funcs(template.FuncMap{"path": func() string { return "" }}).Parse(`[{{path}}]`)))
+
+		for _, p := range []string{"/a", "/b"} {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, p, nil)
+
+			assert.NoError(t, hime.NewAppContext(app, w, r).View("index", nil))
+			assert.Equal(t, w.Body.String(), "["+p+"]")
+		}
+	})
+
+	t.Run("View with context funcs without context", func(t *testing.T) {
+		app := hime.New()
+		app.ContextFunc("path", func(ctx *hime.Context) interface{} {
+			return func() string { return ctx.URL.Path }
+		})
+		app.Template().Parse("index", `{{path}}`)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/a", nil)
+		assert.NoError(t, hime.NewAppContext(app, w, r).View("index", nil))
+
+		var b bytes.Buffer
+		err := app.RenderView(&b, "index", nil)
+		var errNoCtx *hime.ErrNoContext
+		if assert.True(t, errors.As(err, &errNoCtx)) {
+			assert.Equal(t, "path", errNoCtx.Func)
+		}
+	})
+
+	t.Run("View with context funcs concurrently", func(t *testing.T) {
+		app := hime.New()
+		app.ContextFunc("path", func(ctx *hime.Context) interface{} {
+			return func() string { return ctx.URL.Path }
+		})
+		app.Template().Minify().Parse("index", `<p>{{path}}</p>`)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				p := fmt.Sprintf("/%d", i)
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest(http.MethodGet, p, nil)
+				ctx := hime.NewAppContext(app, w, r)
+
+				assert.NoError(t, ctx.View("index", nil))
+				assert.Equal(t, w.Body.String(), "<p>"+p)
+			}(i)
+		}
+		wg.Wait()
+	})
+
+	t.Run("View with view engine", func(t *testing.T) {
+		app := hime.New()
+		app.ETag = true
+		app.Template().Parse("index", `html {{.}}`)
+		app.ViewEngine(stringViewEngine{"index": "engine", "other": "other %v"})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		ctx := hime.NewAppContext(app, w, r)
+		assert.NoError(t, ctx.View("index", "data"))
+		assert.Equal(t, w.Body.String(), "html data", "app's templates must take precedence")
+
+		w = httptest.NewRecorder()
+		ctx = hime.NewAppContext(app, w, r)
+		assert.NoError(t, ctx.View("other", "data"))
+		assert.Equal(t, w.Header().Get("Content-Type"), "text/html; charset=utf-8")
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+		assert.Equal(t, w.Body.String(), "other data")
+
+		assert.IsType(t, &hime.ErrTemplateNotFound{}, ctx.View("not-exists", nil))
+	})
+
+	t.Run("View with minify view engine", func(t *testing.T) {
+		app := hime.New()
+		app.ViewEngine(hime.MinifyViewEngine(stringViewEngine{"index": "  <p>  %v  </p>  "}))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		ctx := hime.NewAppContext(app, w, r)
+		assert.NoError(t, ctx.View("index", "data"))
+		assert.Equal(t, w.Body.String(), "<p>data")
+	})
+
+	t.Run("View with minify view", func(t *testing.T) {
+		app := hime.New()
+		app.MinifyView(true)
+		app.ViewEngine(stringViewEngine{"index": "  <p>  %v  </p>  "})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		ctx := hime.NewAppContext(app, w, r)
+		assert.NoError(t, ctx.View("index", "data"))
+		assert.Equal(t, w.Body.String(), "<p>data")
+
+		var b bytes.Buffer
+		assert.NoError(t, app.RenderView(&b, "index", "data"))
+		assert.Equal(t, b.String(), "<p>data")
+	})
+
 	t.Run("BindJSON", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(`{"a":1}`)))
@@ -751,3 +934,15 @@ func TestContext(t *testing.T) {
 		assert.Equal(t, 1, body.A)
 	})
 }
+
+type stringViewEngine map[string]string
+
+func (e stringViewEngine) HasView(name string) bool {
+	_, ok := e[name]
+	return ok
+}
+
+func (e stringViewEngine) ExecuteView(ctx *hime.Context, w io.Writer, name string, data interface{}) error {
+	_, err := fmt.Fprintf(w, e[name], data)
+	return err
+}
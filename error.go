@@ -3,11 +3,14 @@ package hime
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // Errors
 var (
-	ErrAppNotFound = errors.New("hime: app not found")
+	ErrAppNotFound      = errors.New("hime: app not found")
+	ErrSignatureInvalid = errors.New("hime: invalid signature")
 )
 
 // ErrRouteNotFound is the error for route not found
@@ -23,6 +26,26 @@ func newErrRouteNotFound(route string) error {
 	return &ErrRouteNotFound{route}
 }
 
+// ErrRouteParamMissing is the error for route's named param not given
+type ErrRouteParamMissing struct {
+	Route string
+	Param string
+}
+
+func (err *ErrRouteParamMissing) Error() string {
+	return fmt.Sprintf("hime: route '%s' missing param '%s'", err.Route, err.Param)
+}
+
+// ErrRouteParamExtra is the error for route's param given more than route's named params
+type ErrRouteParamExtra struct {
+	Route string
+	Param string
+}
+
+func (err *ErrRouteParamExtra) Error() string {
+	return fmt.Sprintf("hime: route '%s' extra param '%s'", err.Route, err.Param)
+}
+
 // ErrTemplateNotFound is the error for template not found
 type ErrTemplateNotFound struct {
 	Name string
@@ -36,6 +59,19 @@ func newErrTemplateNotFound(name string) error {
 	return &ErrTemplateNotFound{name}
 }
 
+// ErrNoContext is the error for context func called while render template without request's context
+type ErrNoContext struct {
+	Func string
+}
+
+func (err *ErrNoContext) Error() string {
+	return fmt.Sprintf("hime: template func '%s' requires context", err.Func)
+}
+
+func newErrNoContext(name string) error {
+	return &ErrNoContext{name}
+}
+
 // ErrTemplateDuplicate is the error for template duplicate
 type ErrTemplateDuplicate struct {
 	Name string
@@ -49,6 +85,79 @@ func newErrTemplateDuplicate(name string) error {
 	return &ErrTemplateDuplicate{name}
 }
 
+// ErrAssetNotFound is the error for asset not found
+type ErrAssetNotFound struct {
+	Name string
+}
+
+func (err *ErrAssetNotFound) Error() string {
+	return fmt.Sprintf("hime: asset '%s' not found", err.Name)
+}
+
+func newErrAssetNotFound(name string) error {
+	return &ErrAssetNotFound{name}
+}
+
+// ErrSignatureExpired is the error for signed url expired
+type ErrSignatureExpired struct {
+	Expires time.Time
+}
+
+func (err *ErrSignatureExpired) Error() string {
+	return fmt.Sprintf("hime: signature expired at %s", err.Expires.Format(time.RFC3339))
+}
+
+// ErrVerify is the error for app verification,
+// contains all problems found while verify
+type ErrVerify struct {
+	Errors []error
+}
+
+func (err *ErrVerify) Error() string {
+	xs := make([]string, len(err.Errors))
+	for i, e := range err.Errors {
+		xs[i] = e.Error()
+	}
+	return "hime: verify failed; " + strings.Join(xs, "; ")
+}
+
+// ErrShutdown is the error for shutdown,
+// contains all errors from servers and shutdown hooks
+type ErrShutdown struct {
+	Errors []error
+}
+
+func (err *ErrShutdown) Error() string {
+	xs := make([]string, len(err.Errors))
+	for i, e := range err.Errors {
+		xs[i] = e.Error()
+	}
+	return "hime: shutdown failed; " + strings.Join(xs, "; ")
+}
+
+// appendShutdownError appends err to errs, flattens ErrShutdown
+func appendShutdownError(errs []error, err error) []error {
+	if err == nil {
+		return errs
+	}
+	if e, ok := err.(*ErrShutdown); ok {
+		return append(errs, e.Errors...)
+	}
+	return append(errs, err)
+}
+
+// newErrShutdown returns nil for no error,
+// the error itself for single error, or ErrShutdown
+func newErrShutdown(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return &ErrShutdown{errs}
+}
+
 func panicf(format string, a ...interface{}) {
 	panic(fmt.Sprintf("hime: "+format, a...))
 }
@@ -27,7 +27,8 @@ func (gs *GracefulShutdown) Wait(d time.Duration) *GracefulShutdown {
 	return gs
 }
 
-// Notify calls fn when receive terminate signal from os
+// Notify calls fn when receive terminate signal from os,
+// fn is called in new goroutine without wait, see App.OnShutdown
 func (gs *GracefulShutdown) Notify(fn func()) *GracefulShutdown {
 	if fn != nil {
 		gs.notiFns = append(gs.notiFns, fn)
@@ -0,0 +1,232 @@
+package hime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Health returns app's health check,
+// app serves liveness at /healthz and readiness at /readyz
+//
+//	app.Health().
+//		Check("db", time.Second, db.PingContext).
+//		Readiness("/ready")
+func (app *App) Health() *Health {
+	if app.health == nil {
+		app.health = &Health{
+			app:       app,
+			liveness:  "/healthz",
+			readiness: "/readyz",
+		}
+	}
+
+	return app.health
+}
+
+// Health is the liveness and readiness handlers,
+// readiness becomes unavailable when app starts shutdown
+type Health struct {
+	app       *App
+	liveness  string
+	readiness string
+	checks    []*healthCheck
+}
+
+type healthCheck struct {
+	name    string
+	timeout time.Duration
+	fn      func(ctx context.Context) error
+}
+
+// HealthConfig is the health check config
+type HealthConfig struct {
+	Liveness  *string `yaml:"liveness" json:"liveness"`
+	Readiness *string `yaml:"readiness" json:"readiness"`
+}
+
+// Liveness sets path that app serves liveness,
+// empty path disables, paths are not prefixed with base path
+func (h *Health) Liveness(path string) *Health {
+	h.liveness = path
+	return h
+}
+
+// Readiness sets path that app serves readiness,
+// empty path disables, paths are not prefixed with base path
+func (h *Health) Readiness(path string) *Health {
+	h.readiness = path
+	return h
+}
+
+// Check adds named dependency check to readiness,
+// timeout <= 0 runs check without timeout
+func (h *Health) Check(name string, timeout time.Duration, fn func(ctx context.Context) error) *Health {
+	for _, c := range h.checks {
+		if c.name == name {
+			panicf("health check '%s' already exists", name)
+		}
+	}
+
+	h.checks = append(h.checks, &healthCheck{name, timeout, fn})
+	return h
+}
+
+// Config loads health config
+func (h *Health) Config(config HealthConfig) *Health {
+	if config.Liveness != nil {
+		h.Liveness(*config.Liveness)
+	}
+	if config.Readiness != nil {
+		h.Readiness(*config.Readiness)
+	}
+	return h
+}
+
+// LivenessHandler returns handler that reports app is alive
+func (h *Health) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealthReport(w, &healthReport{Status: healthStatusOK})
+	})
+}
+
+// ReadinessHandler returns handler that runs all checks,
+// responds 503 if any check failed or app is shutting down
+func (h *Health) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealthReport(w, h.report(r.Context()))
+	})
+}
+
+// serve serves health handlers if r matches, returns false if not match
+func (h *Health) serve(w http.ResponseWriter, r *http.Request) bool {
+	switch r.URL.Path {
+	case "":
+		return false
+	case h.liveness:
+		h.LivenessHandler().ServeHTTP(w, r)
+	case h.readiness:
+		h.ReadinessHandler().ServeHTTP(w, r)
+	default:
+		return false
+	}
+	return true
+}
+
+const (
+	healthStatusOK           = "ok"
+	healthStatusError        = "error"
+	healthStatusShuttingDown = "shutting down"
+)
+
+type healthReport struct {
+	Status string                        `json:"status"`
+	Checks map[string]*healthCheckReport `json:"checks,omitempty"`
+}
+
+type healthCheckReport struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+func (h *Health) report(ctx context.Context) *healthReport {
+	if h.app.isShuttingDown() {
+		return &healthReport{Status: healthStatusShuttingDown}
+	}
+
+	rp := healthReport{
+		Status: healthStatusOK,
+		Checks: make(map[string]*healthCheckReport, len(h.checks)),
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for _, c := range h.checks {
+		c := c
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			x := c.run(ctx)
+			mu.Lock()
+			rp.Checks[c.name] = x
+			if x.Status != healthStatusOK {
+				rp.Status = healthStatusError
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return &rp
+}
+
+func (c *healthCheck) run(ctx context.Context) *healthCheckReport {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- c.fn(ctx)
+	}()
+
+	// do not wait check that ignores context
+	var err error
+	select {
+	case err = <-errChan:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	x := healthCheckReport{
+		Status:   healthStatusOK,
+		Duration: time.Since(start).String(),
+	}
+	if err != nil {
+		x.Status = healthStatusError
+		x.Error = err.Error()
+	}
+	return &x
+}
+
+func writeHealthReport(w http.ResponseWriter, rp *healthReport) {
+	// healthReport always marshal success
+	b, _ := json.Marshal(rp)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if rp.Status != healthStatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	w.Write(b)
+}
+
+// isShuttingDown returns true if app started shutdown
+func (app *App) isShuttingDown() bool {
+	return atomic.LoadInt32(&app.shuttingDown) == 1
+}
+
+func (app *App) setShuttingDown() {
+	atomic.StoreInt32(&app.shuttingDown, 1)
+}
+
+func cloneHealth(app *App, h *Health) *Health {
+	if h == nil {
+		return nil
+	}
+
+	x := *h
+	x.app = app
+	x.checks = append([]*healthCheck(nil), h.checks...)
+	return &x
+}
@@ -0,0 +1,130 @@
+package hime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	serve := func(app *App, path string) (int, *healthReport) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		app.ServeHTTP(w, r)
+
+		var rp healthReport
+		json.Unmarshal(w.Body.Bytes(), &rp)
+		return w.Code, &rp
+	}
+
+	t.Run("Default paths", func(t *testing.T) {
+		app := New().BasePath("/admin").Handler(stringHandler("ok"))
+		app.Health()
+
+		code, rp := serve(app, "/healthz")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "ok", rp.Status)
+
+		code, rp = serve(app, "/readyz")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "ok", rp.Status)
+	})
+
+	t.Run("Not enabled", func(t *testing.T) {
+		app := New().Handler(http.NotFoundHandler())
+
+		code, _ := serve(app, "/healthz")
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("Checks", func(t *testing.T) {
+		app := New()
+		app.Health().
+			Liveness("/live").
+			Readiness("/ready").
+			Check("db", time.Second, func(ctx context.Context) error { return nil }).
+			Check("cache", time.Second, func(ctx context.Context) error { return errors.New("connection refused") }).
+			Check("slow", 10*time.Millisecond, func(ctx context.Context) error {
+				time.Sleep(time.Second)
+				return nil
+			})
+
+		code, rp := serve(app, "/live")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Empty(t, rp.Checks)
+
+		code, rp = serve(app, "/ready")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "error", rp.Status)
+		if assert.Len(t, rp.Checks, 3) {
+			assert.Equal(t, "ok", rp.Checks["db"].Status)
+			assert.Equal(t, "error", rp.Checks["cache"].Status)
+			assert.Equal(t, "connection refused", rp.Checks["cache"].Error)
+			assert.Equal(t, "error", rp.Checks["slow"].Status)
+			assert.Equal(t, context.DeadlineExceeded.Error(), rp.Checks["slow"].Error)
+		}
+
+		assert.Panics(t, func() { app.Health().Check("db", 0, nil) })
+	})
+
+	t.Run("Shutdown", func(t *testing.T) {
+		app := New()
+		app.Health()
+		app.GracefulShutdown().Wait(200 * time.Millisecond)
+
+		go app.Shutdown(context.Background())
+		time.Sleep(50 * time.Millisecond)
+
+		code, rp := serve(app, "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "shutting down", rp.Status)
+
+		code, _ = serve(app, "/healthz")
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("Apps shutdown", func(t *testing.T) {
+		app1, app2 := New(), New()
+		app1.Health()
+		app2.Health()
+
+		Merge(app1, app2).Shutdown(context.Background())
+
+		code, _ := serve(app1, "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		code, _ = serve(app2, "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+	})
+
+	t.Run("Handler", func(t *testing.T) {
+		app := New()
+		app.Health().Liveness("").Readiness("")
+
+		code, _ := serve(app, "/readyz")
+		assert.Equal(t, http.StatusNotFound, code)
+
+		w := httptest.NewRecorder()
+		app.Health().ReadinessHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		app := New().ParseConfig([]byte(`
+health:
+  liveness: /live
+  readiness: ""`))
+		assert.Equal(t, "/live", app.health.liveness)
+		assert.Equal(t, "", app.health.readiness)
+
+		x := app.Clone()
+		assert.Equal(t, "/live", x.health.liveness)
+		assert.Equal(t, x, x.health.app)
+	})
+}
@@ -0,0 +1,144 @@
+package hime
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// OnStart adds fn to be called in registered order when ListenAndServe,
+// after listeners created and before serve,
+// ListenAndServe returns fn's error without serve
+func (app *App) OnStart(fn func(ctx context.Context) error) *App {
+	if fn != nil {
+		app.onStart = append(app.onStart, fn)
+	}
+	return app
+}
+
+// OnShutdown adds fn to be called in reverse registered order when Shutdown,
+// after server stopped accepting requests and all connections are idle,
+// fn gets graceful shutdown's timeout apart from server's timeout,
+// errors from fn return from Shutdown and ListenAndServe
+func (app *App) OnShutdown(fn func(ctx context.Context) error) *App {
+	if fn != nil {
+		app.onShutdown = append(app.onShutdown, fn)
+	}
+	return app
+}
+
+func (app *App) runStartHooks(ctx context.Context) error {
+	for _, fn := range app.onStart {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runShutdownHooks runs shutdown hooks in reverse order,
+// stops waiting hooks when ctx done
+func (app *App) runShutdownHooks(ctx context.Context) []error {
+	var errs []error
+	for i := len(app.onShutdown) - 1; i >= 0; i-- {
+		fn := app.onShutdown[i]
+
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- fn(ctx)
+		}()
+
+		select {
+		case err := <-errChan:
+			if err != nil {
+				errs = append(errs, err)
+			}
+		case <-ctx.Done():
+			return append(errs, ctx.Err())
+		}
+	}
+	return errs
+}
+
+// shutdownState is the result of app's shutdown
+type shutdownState struct {
+	once    sync.Once
+	mu      sync.Mutex
+	started bool
+	done    chan struct{}
+	err     error
+}
+
+func (s *shutdownState) wait() chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.done == nil {
+		s.done = make(chan struct{})
+	}
+	return s.done
+}
+
+// isStarted returns true if shutdown was called
+func (s *shutdownState) isStarted() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.started
+}
+
+// do runs fn only once, other calls wait for fn's result
+func (s *shutdownState) do(fn func() error) error {
+	done := s.wait()
+
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.started = true
+		s.mu.Unlock()
+
+		s.err = fn()
+		close(done)
+	})
+
+	<-done
+	return s.err
+}
+
+// shutdown shutdowns app without graceful shutdown's wait,
+// only first call do shutdown, other calls wait for the result
+func (app *App) shutdown(ctx context.Context, timeout time.Duration) error {
+	return app.shutdownState.do(func() error {
+		return app.shutdownServer(ctx, timeout)
+	})
+}
+
+// shutdownServer shutdowns server then runs shutdown hooks,
+// server drain and shutdown hooks each have their own timeout,
+// timeout <= 0 uses only ctx
+func (app *App) shutdownServer(ctx context.Context, timeout time.Duration) error {
+	drainCtx, cancel := withShutdownTimeout(ctx, timeout)
+	errs := appendShutdownError(nil, app.srv.Shutdown(drainCtx))
+	cancel()
+
+	// drain may use all of its timeout,
+	// shutdown hooks must not get drain's expired context
+	hookCtx, cancel := withShutdownTimeout(ctx, timeout)
+	errs = append(errs, app.runShutdownHooks(hookCtx)...)
+	cancel()
+
+	return newErrShutdown(errs)
+}
+
+func withShutdownTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+func closeListeners(lns []net.Listener) {
+	for _, ln := range lns {
+		ln.Close()
+	}
+}
@@ -0,0 +1,300 @@
+package hime
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLifecycleHooks(t *testing.T) {
+	t.Run("OnStart", func(t *testing.T) {
+		var calls []string
+		addr := freeAddr(t)
+		app := New().Address(addr).
+			OnStart(func(ctx context.Context) error {
+				calls = append(calls, "1")
+				return nil
+			}).
+			OnStart(func(ctx context.Context) error {
+				calls = append(calls, "2")
+				return errors.New("start failed")
+			}).
+			OnStart(func(ctx context.Context) error {
+				calls = append(calls, "3")
+				return nil
+			})
+
+		err := app.ListenAndServe()
+		assert.EqualError(t, err, "start failed")
+		assert.Equal(t, []string{"1", "2"}, calls)
+
+		// listener must be closed
+		ln, err := net.Listen("tcp", addr)
+		if assert.NoError(t, err) {
+			ln.Close()
+		}
+	})
+
+	t.Run("OnShutdown", func(t *testing.T) {
+		var calls []string
+		errHook := errors.New("flush failed")
+		addr := freeAddr(t)
+		app := New().Address(addr).Handler(stringHandler("ok")).
+			OnShutdown(func(ctx context.Context) error {
+				calls = append(calls, "1")
+				return nil
+			}).
+			OnShutdown(func(ctx context.Context) error {
+				calls = append(calls, "2")
+				return errHook
+			}).
+			OnShutdown(func(ctx context.Context) error {
+				calls = append(calls, "3")
+				return nil
+			})
+		app.GracefulShutdown()
+
+		errChan := make(chan error, 1)
+		go func() { errChan <- app.ListenAndServe() }()
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, "ok", get(t, addr))
+
+		assert.Equal(t, errHook, app.Shutdown(context.Background()))
+		assert.Equal(t, []string{"3", "2", "1"}, calls)
+
+		select {
+		case err := <-errChan:
+			assert.Equal(t, errHook, err)
+		case <-time.After(time.Second):
+			require.FailNow(t, "ListenAndServe not return")
+		}
+
+		// hooks run only once
+		assert.Equal(t, errHook, app.Shutdown(context.Background()))
+		assert.Len(t, calls, 3)
+	})
+
+	t.Run("Without graceful shutdown", func(t *testing.T) {
+		app := New().Address(freeAddr(t))
+
+		errChan := make(chan error, 1)
+		go func() { errChan <- app.ListenAndServe() }()
+		time.Sleep(50 * time.Millisecond)
+
+		assert.NoError(t, app.Shutdown(context.Background()))
+		assert.Equal(t, http.ErrServerClosed, <-errChan)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		var calls []string
+		app := New().
+			OnShutdown(func(ctx context.Context) error {
+				calls = append(calls, "1")
+				return nil
+			}).
+			OnShutdown(func(ctx context.Context) error {
+				time.Sleep(time.Second)
+				return nil
+			})
+		app.GracefulShutdown().Timeout(50 * time.Millisecond)
+
+		start := time.Now()
+		err := app.Shutdown(context.Background())
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Less(t, int64(time.Since(start)), int64(500*time.Millisecond))
+		assert.Empty(t, calls)
+	})
+
+	t.Run("Drain timeout", func(t *testing.T) {
+		var calls []string
+		addr := freeAddr(t)
+		app := New().Address(addr).
+			Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(300 * time.Millisecond)
+			})).
+			OnShutdown(func(ctx context.Context) error {
+				if ctx.Err() == nil {
+					calls = append(calls, "1")
+				}
+				return nil
+			})
+		app.GracefulShutdown().Timeout(50 * time.Millisecond)
+
+		go app.ListenAndServe()
+		time.Sleep(50 * time.Millisecond)
+		go http.Get("http://" + addr)
+		time.Sleep(50 * time.Millisecond)
+
+		err := app.Shutdown(context.Background())
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Equal(t, []string{"1"}, calls, "hooks must run after drain timeout")
+	})
+
+	t.Run("Shutdown once", func(t *testing.T) {
+		var notified int32
+		app := New()
+		app.GracefulShutdown().Notify(func() { atomic.AddInt32(&notified, 1) })
+
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, app.Shutdown(context.Background()))
+			}()
+		}
+		wg.Wait()
+		assert.NoError(t, app.Shutdown(context.Background()))
+
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&notified))
+	})
+
+	t.Run("Server closed", func(t *testing.T) {
+		for name, stop := range map[string]func(srv *http.Server){
+			"Close":    func(srv *http.Server) { srv.Close() },
+			"Shutdown": func(srv *http.Server) { srv.Shutdown(context.Background()) },
+		} {
+			t.Run(name, func(t *testing.T) {
+				var called int32
+				app := New().Address(freeAddr(t)).
+					OnShutdown(func(ctx context.Context) error {
+						atomic.AddInt32(&called, 1)
+						return nil
+					})
+				app.GracefulShutdown()
+
+				errChan := make(chan error, 1)
+				go func() { errChan <- app.ListenAndServe() }()
+				time.Sleep(50 * time.Millisecond)
+
+				stop(app.Server())
+
+				select {
+				case err := <-errChan:
+					assert.Equal(t, http.ErrServerClosed, err)
+					assert.Equal(t, int32(1), atomic.LoadInt32(&called), "shutdown hooks must run")
+				case <-time.After(time.Second):
+					require.FailNow(t, "ListenAndServe not return")
+				}
+			})
+		}
+	})
+
+	t.Run("Serve failed", func(t *testing.T) {
+		var calls []string
+		errHook := errors.New("flush failed")
+		app := New().
+			OnShutdown(func(ctx context.Context) error {
+				calls = append(calls, "1")
+				return errHook
+			})
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		errAccept := errors.New("accept failed")
+
+		errChan := make(chan error, 1)
+		go func() { errChan <- app.run([]net.Listener{ln, failListener{ln, errAccept}}, nil) }()
+
+		select {
+		case err := <-errChan:
+			if assert.IsType(t, &ErrShutdown{}, err) {
+				assert.Equal(t, []error{errAccept, errHook}, err.(*ErrShutdown).Errors)
+			}
+			assert.Equal(t, []string{"1"}, calls)
+		case <-time.After(time.Second):
+			require.FailNow(t, "run not return")
+		}
+	})
+
+	t.Run("Apps start failed", func(t *testing.T) {
+		var calls []string
+		app1 := New().Address(freeAddr(t)).
+			OnShutdown(func(ctx context.Context) error {
+				calls = append(calls, "shutdown1")
+				return nil
+			})
+		app2 := New().Address(freeAddr(t)).
+			OnStart(func(ctx context.Context) error { return errors.New("start failed") }).
+			OnShutdown(func(ctx context.Context) error {
+				calls = append(calls, "shutdown2")
+				return nil
+			})
+
+		err := Merge(app1, app2).ListenAndServe()
+		assert.EqualError(t, err, "start failed")
+		assert.Equal(t, []string{"shutdown1"}, calls)
+	})
+
+	t.Run("Apps", func(t *testing.T) {
+		var calls []string
+		err1, err2 := errors.New("app1 failed"), errors.New("app2 failed")
+		app1 := New().Address(freeAddr(t)).
+			OnStart(func(ctx context.Context) error {
+				calls = append(calls, "start1")
+				return nil
+			}).
+			OnShutdown(func(ctx context.Context) error { return err1 })
+		app2 := New().Address(freeAddr(t)).
+			OnStart(func(ctx context.Context) error {
+				calls = append(calls, "start2")
+				return nil
+			}).
+			OnShutdown(func(ctx context.Context) error { return err2 })
+		apps := Merge(app1, app2)
+		apps.GracefulShutdown()
+
+		errChan := make(chan error, 1)
+		go func() { errChan <- apps.ListenAndServe() }()
+		time.Sleep(50 * time.Millisecond)
+
+		err := apps.Shutdown(context.Background())
+		if assert.IsType(t, &ErrShutdown{}, err) {
+			assert.Equal(t, []error{err1, err2}, err.(*ErrShutdown).Errors)
+			assert.EqualError(t, err, "hime: shutdown failed; app1 failed; app2 failed")
+		}
+
+		select {
+		case err := <-errChan:
+			if assert.IsType(t, &ErrShutdown{}, err) {
+				assert.ElementsMatch(t, []error{err1, err2}, err.(*ErrShutdown).Errors)
+			}
+			assert.Equal(t, []string{"start1", "start2"}, calls)
+		case <-time.After(time.Second):
+			require.FailNow(t, "ListenAndServe not return")
+		}
+	})
+
+	t.Run("Clone", func(t *testing.T) {
+		app := New().
+			OnStart(func(ctx context.Context) error { return nil }).
+			OnShutdown(func(ctx context.Context) error { return nil })
+
+		x := app.Clone()
+		assert.Len(t, x.onStart, 1)
+		assert.Len(t, x.onShutdown, 1)
+	})
+}
+
+// failListener is the listener that Accept always fails
+type failListener struct {
+	net.Listener
+	err error
+}
+
+func (ln failListener) Accept() (net.Conn, error) {
+	return nil, ln.err
+}
+
+func (ln failListener) Close() error {
+	return nil
+}
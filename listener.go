@@ -1,8 +1,17 @@
 package hime
 
 import (
+	"encoding/json"
+	"fmt"
 	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
 	"time"
+
+	reuseport "github.com/kavu/go_reuseport"
 )
 
 // tcpKeepAliveListener edited from http.tcpKeepAliveListener
@@ -20,3 +29,220 @@ func (ln tcpKeepAliveListener) Accept() (net.Conn, error) {
 	tc.SetKeepAlivePeriod(ln.period)
 	return tc, nil
 }
+
+// listenAddress is the parsed listen address
+type listenAddress struct {
+	network string
+	address string
+
+	// unix socket file's mode and ownership
+	mode  os.FileMode
+	owner string
+	group string
+}
+
+// parseListenAddress parses listen address,
+// address without scheme is tcp address (e.g. :8080)
+//
+//	tcp://0.0.0.0:8080
+//	tcp4://127.0.0.1:8080
+//	tcp6://[::]:8080
+//	unix:///run/app.sock?mode=0660&owner=www-data&group=www-data
+func parseListenAddress(addr string) (*listenAddress, error) {
+	if !strings.Contains(addr, "://") {
+		if addr == "" {
+			addr = ":http"
+		}
+		return &listenAddress{network: "tcp", address: addr}, nil
+	}
+
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	a := listenAddress{network: u.Scheme}
+	switch u.Scheme {
+	case "tcp", "tcp4", "tcp6":
+		if u.Path != "" || u.RawQuery != "" {
+			return nil, fmt.Errorf("tcp address can not have path or query")
+		}
+		a.address = u.Host
+		if a.address == "" {
+			a.address = ":http"
+		}
+	case "unix":
+		a.address = u.Host + u.Path
+		if a.address == "" {
+			return nil, fmt.Errorf("unix address requires path")
+		}
+
+		q := u.Query()
+		if s := q.Get("mode"); s != "" {
+			mode, err := strconv.ParseUint(s, 8, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid mode '%s'", s)
+			}
+			a.mode = os.FileMode(mode)
+		}
+		a.owner = q.Get("owner")
+		a.group = q.Get("group")
+	default:
+		return nil, fmt.Errorf("unsupported network '%s'", u.Scheme)
+	}
+	return &a, nil
+}
+
+func (a *listenAddress) listen(reusePort bool) (net.Listener, error) {
+	if a.network == "unix" {
+		return a.listenUnix()
+	}
+	if reusePort {
+		return reuseport.NewReusablePortListener(a.network, a.address)
+	}
+	return net.Listen(a.network, a.address)
+}
+
+func (a *listenAddress) listenUnix() (net.Listener, error) {
+	err := removeStaleSocket(a.address)
+	if err != nil {
+		return nil, err
+	}
+
+	ln, err := net.Listen("unix", a.address)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.setSocketPermission()
+	if err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// removeStaleSocket removes socket file that left from previous process,
+// returns error if the socket still in use
+func removeStaleSocket(path string) error {
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	conn, err := net.Dial("unix", path)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("%s already in use", path)
+	}
+	return os.Remove(path)
+}
+
+func (a *listenAddress) setSocketPermission() error {
+	if a.mode != 0 {
+		err := os.Chmod(a.address, a.mode)
+		if err != nil {
+			return err
+		}
+	}
+
+	if a.owner == "" && a.group == "" {
+		return nil
+	}
+
+	uid, gid := -1, -1
+	if a.owner != "" {
+		id, err := lookupID(a.owner, func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		})
+		if err != nil {
+			return err
+		}
+		uid = id
+	}
+	if a.group != "" {
+		id, err := lookupID(a.group, func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		})
+		if err != nil {
+			return err
+		}
+		gid = id
+	}
+	return os.Chown(a.address, uid, gid)
+}
+
+// lookupID returns numeric id or looks up id from name
+func lookupID(s string, lookup func(name string) (string, error)) (int, error) {
+	if id, err := strconv.Atoi(s); err == nil {
+		return id, nil
+	}
+
+	id, err := lookup(s)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(id)
+}
+
+// keepUnixSocket prevents unix listeners from remove socket file when close,
+// new process still uses socket file after upgrade
+func keepUnixSocket(lns []net.Listener) {
+	for _, ln := range lns {
+		if ul, ok := ln.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+}
+
+// Addresses is the list of listen address,
+// unmarshals from a string or list of string
+type Addresses []string
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (addrs *Addresses) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if unmarshal(&s) == nil {
+		*addrs = Addresses{s}
+		return nil
+	}
+
+	var xs []string
+	err := unmarshal(&xs)
+	if err != nil {
+		return err
+	}
+	*addrs = xs
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (addrs *Addresses) UnmarshalJSON(b []byte) error {
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		*addrs = Addresses{s}
+		return nil
+	}
+
+	var xs []string
+	err := json.Unmarshal(b, &xs)
+	if err != nil {
+		return err
+	}
+	*addrs = xs
+	return nil
+}
@@ -0,0 +1,160 @@
+package hime
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListenAddress(t *testing.T) {
+	cases := []struct {
+		In      string
+		Network string
+		Address string
+		Mode    os.FileMode
+		Owner   string
+		Group   string
+	}{
+		{"", "tcp", ":http", 0, "", ""},
+		{":8080", "tcp", ":8080", 0, "", ""},
+		{"127.0.0.1:8080", "tcp", "127.0.0.1:8080", 0, "", ""},
+		{"tcp://0.0.0.0:8080", "tcp", "0.0.0.0:8080", 0, "", ""},
+		{"tcp4://127.0.0.1:8080", "tcp4", "127.0.0.1:8080", 0, "", ""},
+		{"tcp6://[::]:8080", "tcp6", "[::]:8080", 0, "", ""},
+		{"tcp://", "tcp", ":http", 0, "", ""},
+		{"unix:///run/app.sock", "unix", "/run/app.sock", 0, "", ""},
+		{"unix://app.sock", "unix", "app.sock", 0, "", ""},
+		{"unix:///run/app.sock?mode=0660&owner=www&group=1000", "unix", "/run/app.sock", 0660, "www", "1000"},
+	}
+
+	for _, c := range cases {
+		a, err := parseListenAddress(c.In)
+		if assert.NoError(t, err, c.In) {
+			assert.Equal(t, c.Network, a.network, c.In)
+			assert.Equal(t, c.Address, a.address, c.In)
+			assert.Equal(t, c.Mode, a.mode, c.In)
+			assert.Equal(t, c.Owner, a.owner, c.In)
+			assert.Equal(t, c.Group, a.group, c.In)
+		}
+	}
+
+	for _, s := range []string{
+		"udp://:8080",
+		"unix://",
+		"unix:///run/app.sock?mode=999",
+		"tcp://:8080/path",
+	} {
+		_, err := parseListenAddress(s)
+		assert.Error(t, err, s)
+	}
+
+	assert.Panics(t, func() { New().Address("udp://:8080") })
+}
+
+func TestMultipleAddresses(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix socket file mode not supported")
+	}
+
+	sock := filepath.Join(t.TempDir(), "app.sock")
+	tcpAddr := freeAddr(t)
+
+	app := New().
+		Address("tcp://"+tcpAddr, "unix://"+sock+"?mode=0600").
+		Handler(stringHandler("ok"))
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- app.ListenAndServe() }()
+	time.Sleep(100 * time.Millisecond)
+
+	fi, err := os.Stat(sock)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	assert.Equal(t, "ok", get(t, tcpAddr))
+
+	client := http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return net.Dial("unix", sock)
+			},
+		},
+	}
+	resp, err := client.Get("http://unix/")
+	if assert.NoError(t, err) {
+		b, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "ok", string(b))
+	}
+
+	t.Run("Prefork", func(t *testing.T) {
+		err := New().Address("unix://" + sock).Prefork(2).ListenAndServe()
+		assert.EqualError(t, err, "hime: prefork can not listen on unix socket '"+sock+"'")
+	})
+
+	t.Run("Socket in use", func(t *testing.T) {
+		_, err := New().Address("unix://" + sock).listen(0)
+		assert.Error(t, err)
+	})
+
+	assert.NoError(t, app.Shutdown(context.Background()))
+	assert.Equal(t, http.ErrServerClosed, <-errChan)
+
+	_, err = os.Stat(sock)
+	assert.True(t, os.IsNotExist(err))
+
+	t.Run("Remove stale socket", func(t *testing.T) {
+		ln, err := net.Listen("unix", sock)
+		require.NoError(t, err)
+		ln.(*net.UnixListener).SetUnlinkOnClose(false)
+		ln.Close()
+
+		lns, err := New().Address("unix://" + sock).listen(0)
+		if assert.NoError(t, err) {
+			lns[0].Close()
+		}
+	})
+}
+
+func TestAddressesConfig(t *testing.T) {
+	app := New().ParseConfig([]byte(`
+server:
+  addrs: ["unix:///tmp/app.sock?mode=0660", tcp://:8080]`))
+	assert.Len(t, app.addrs, 2)
+	assert.Equal(t, ":8080", app.srv.Addr, "server's addr must be tcp address")
+	assert.Equal(t, "unix", app.addrs[0].network)
+	assert.Equal(t, os.FileMode(0660), app.addrs[0].mode)
+	assert.Len(t, app.Clone().addrs, 2)
+
+	app = New().ParseConfig([]byte(`
+server:
+  addr: :8080`))
+	assert.Len(t, app.addrs, 1)
+	assert.Equal(t, ":8080", app.srv.Addr)
+
+	app = New().ParseConfig([]byte(`
+server:
+  addr: :8080
+  addrs: unix:///tmp/app.sock`))
+	assert.Len(t, app.addrs, 2)
+	assert.Equal(t, ":8080", app.srv.Addr)
+
+	app = New().Address("unix:///tmp/app.sock")
+	assert.Equal(t, "", app.srv.Addr)
+
+	var addrs Addresses
+	assert.NoError(t, json.Unmarshal([]byte(`":8080"`), &addrs))
+	assert.Equal(t, Addresses{":8080"}, addrs)
+	assert.NoError(t, json.Unmarshal([]byte(`[":8080", "unix:///tmp/app.sock"]`), &addrs))
+	assert.Equal(t, Addresses{":8080", "unix:///tmp/app.sock"}, addrs)
+}
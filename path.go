@@ -62,7 +62,13 @@ func buildPath(base string, params ...interface{}) string {
 			mergeValueWithMapInterface(ps, v)
 		case *Param:
 			ps[v.Name] = append(ps[v.Name], fmt.Sprint(v.Value))
+		case Param:
+			ps[v.Name] = append(ps[v.Name], fmt.Sprint(v.Value))
 		default:
+			if isQueryStruct(p) {
+				mergeValueWithStruct(ps, p)
+				break
+			}
 			xs = append(xs, strings.TrimPrefix(fmt.Sprint(p), "/"))
 		}
 	}
@@ -3,6 +3,7 @@ package hime
 import (
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -50,6 +51,7 @@ func TestBuildPathParams(t *testing.T) {
 		{"/a", []interface{}{"/b/", map[string]string{"id": "10"}}, "/a/b?id=10"},
 		{"/a", []interface{}{"/b/", map[string]interface{}{"id": 10}}, "/a/b?id=10"},
 		{"/a", []interface{}{"/b", &Param{Name: "id", Value: 3456}}, "/a/b?id=3456"},
+		{"/a", []interface{}{"/b", Param{Name: "id", Value: 3456}}, "/a/b?id=3456"},
 	}
 
 	for _, c := range cases {
@@ -82,3 +84,63 @@ func TestSafeRedirectPath(t *testing.T) {
 		assert.Equal(t, SafeRedirectPath(c.Input), c.Output)
 	}
 }
+
+type testQueryLevel int
+
+func (l *testQueryLevel) MarshalText() ([]byte, error) {
+	return []byte([]string{"low", "high"}[*l]), nil
+}
+
+type testQueryPage struct {
+	Page  int `query:"page,omitempty"`
+	Limit int `query:"limit,omitempty"`
+}
+
+type testQueryFilter struct {
+	testQueryPage
+	Query   string         `query:"q,omitempty"`
+	Tags    []string       `query:"tag"`
+	Since   time.Time      `query:"since,omitempty"`
+	Until   *time.Time     `query:"until"`
+	Active  *bool          `query:"active,omitempty"`
+	Level   testQueryLevel `query:"level"`
+	Name    string
+	Ignored string `query:"-"`
+	private string
+}
+
+func TestBuildPathStruct(t *testing.T) {
+	t.Parallel()
+
+	active := false
+	until := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := []struct {
+		Name   string
+		Params []interface{}
+		Output string
+	}{
+		{"Empty", []interface{}{testQueryFilter{}}, "/a?Name=&level=low"},
+		{"Nil pointer", []interface{}{(*testQueryFilter)(nil)}, "/a"},
+		{"Full", []interface{}{&testQueryFilter{
+			testQueryPage: testQueryPage{Page: 2},
+			Query:         "a b",
+			Tags:          []string{"x", "y"},
+			Since:         time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			Until:         &until,
+			Active:        &active,
+			Level:         1,
+			Name:          "n",
+			Ignored:       "i",
+			private:       "p",
+		}}, "/a?Name=n&active=false&level=high&page=2&q=a+b&since=2020-01-01T00%3A00%3A00Z&tag=x&tag=y&until=2020-01-02T03%3A04%3A05Z"},
+		{"With path and other params", []interface{}{"b", testQueryPage{Page: 1}, &Param{"x", 1}}, "/a/b?page=1&x=1"},
+		{"Time as path", []interface{}{until}, "/a/" + until.String()},
+		{"Untagged struct as path", []interface{}{struct{ A, B int }{1, 2}}, "/a/{1 2}"},
+		{"Embedded tagged struct", []interface{}{struct{ testQueryPage }{testQueryPage{Page: 3}}}, "/a?page=3"},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.Output, buildPath("/a", c.Params...), c.Name)
+	}
+}
@@ -0,0 +1,185 @@
+package hime
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// preforkBackoff is the delay before restart crashed child process,
+// doubles on every crash until preforkMaxBackoff
+var (
+	preforkBackoff    = 100 * time.Millisecond
+	preforkMaxBackoff = 30 * time.Second
+)
+
+// preforkCommand returns command to start child process
+var preforkCommand = func() (*exec.Cmd, error) {
+	p, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(p, os.Args[1:]...), nil
+}
+
+// Prefork starts n child processes when ListenAndServe,
+// each child process listens on the same address using SO_REUSEPORT,
+// so prefork can not use with unix socket address,
+// parent process restarts crashed child and forwards SIGTERM to all children,
+// children shutdown gracefully on SIGTERM and only parent notifies systemd
+//
+// n <= 0 disables prefork
+func (app *App) Prefork(n int) *App {
+	app.prefork = n
+	return app
+}
+
+// isPreforkChild returns true if current process started by prefork parent
+func isPreforkChild() bool {
+	return os.Getenv("HIME_PREFORK_CHILD") != ""
+}
+
+type preforkExit struct {
+	i   int
+	err error
+}
+
+// runPrefork starts and supervises child processes until receive SIGTERM
+func (app *App) runPrefork() error {
+	// children can not share unix socket, each child removes other's socket file
+	for _, a := range app.addrs {
+		if a.network == "unix" {
+			return fmt.Errorf("hime: prefork can not listen on unix socket '%s'", a.address)
+		}
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	var (
+		n        = app.prefork
+		children = make([]*exec.Cmd, n)
+		started  = make([]time.Time, n)
+		backoff  = make([]time.Duration, n)
+		exited   = make(chan preforkExit, n)
+		restart  = make(chan int, n)
+		done     = make(chan struct{})
+		running  int
+		stopping bool
+	)
+	defer close(done)
+
+	start := func(i int) error {
+		started[i] = time.Now()
+
+		cmd, err := preforkCommand()
+		if err != nil {
+			return err
+		}
+		if cmd.Stdout == nil {
+			cmd.Stdout = os.Stdout
+		}
+		if cmd.Stderr == nil {
+			cmd.Stderr = os.Stderr
+		}
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, "HIME_PREFORK_CHILD="+strconv.Itoa(i+1))
+		setPdeathsig(cmd)
+
+		err = cmd.Start()
+		if err != nil {
+			return err
+		}
+		children[i] = cmd
+		running++
+
+		go func() {
+			exited <- preforkExit{i, cmd.Wait()}
+		}()
+		return nil
+	}
+
+	terminate := func() {
+		stopping = true
+		for _, cmd := range children {
+			if cmd == nil {
+				continue
+			}
+			if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+				cmd.Process.Kill()
+			}
+		}
+	}
+
+	scheduleRestart := func(i int, err error) {
+		// reset backoff if child was running long enough
+		if backoff[i] == 0 || time.Since(started[i]) >= preforkMaxBackoff {
+			backoff[i] = preforkBackoff
+		} else {
+			backoff[i] *= 2
+			if backoff[i] > preforkMaxBackoff {
+				backoff[i] = preforkMaxBackoff
+			}
+		}
+
+		d := backoff[i]
+		log.Printf("hime: prefork child %d exited; %v, restart in %v", i+1, err, d)
+		go func() {
+			select {
+			case <-time.After(d):
+				restart <- i
+			case <-done:
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		if err := start(i); err != nil {
+			terminate()
+			for ; running > 0; running-- {
+				<-exited
+			}
+			return err
+		}
+	}
+
+	stopReady := sdReady()
+	defer stopReady()
+
+	for {
+		select {
+		case <-stop:
+			sdStopping(app.gs, len(app.onShutdown) > 0)
+			terminate()
+			if running == 0 {
+				return nil
+			}
+		case x := <-exited:
+			children[x.i] = nil
+			running--
+			if stopping {
+				if running == 0 {
+					return nil
+				}
+				continue
+			}
+
+			scheduleRestart(x.i, x.err)
+		case i := <-restart:
+			if stopping {
+				continue
+			}
+			if err := start(i); err != nil {
+				scheduleRestart(i, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package hime
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setPdeathsig makes child receive SIGTERM when parent dies,
+// so children do not keep serving after parent is killed
+func setPdeathsig(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Pdeathsig = syscall.SIGTERM
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package hime
+
+import (
+	"os/exec"
+)
+
+// parent death signal is only supported on linux
+func setPdeathsig(cmd *exec.Cmd) {}
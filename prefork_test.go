@@ -0,0 +1,143 @@
+//go:build linux
+// +build linux
+
+package hime
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPreforkHelperProcess is the child process that started by prefork
+func TestPreforkHelperProcess(t *testing.T) {
+	addr := os.Getenv("HIME_TEST_PREFORK_ADDR")
+	if addr == "" {
+		return
+	}
+
+	// child shutdowns gracefully on SIGTERM without graceful shutdown config
+	err := New().Address(addr).Prefork(1).Handler(stringHandler(strconv.Itoa(os.Getpid()))).ListenAndServe()
+	if err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// TestPreforkParentHelperProcess is the prefork parent process that killed by test
+func TestPreforkParentHelperProcess(t *testing.T) {
+	addr := os.Getenv("HIME_TEST_PREFORK_PARENT_ADDR")
+	if addr == "" {
+		return
+	}
+
+	preforkCommand = func() (*exec.Cmd, error) {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestPreforkHelperProcess$")
+		cmd.Env = append(os.Environ(), "HIME_TEST_PREFORK_ADDR="+addr)
+		return cmd, nil
+	}
+	New().Address(addr).Prefork(1).ListenAndServe()
+	os.Exit(0)
+}
+
+func waitPreforkPid(addr, skip string) string {
+	for i := 0; i < 50; i++ {
+		time.Sleep(100 * time.Millisecond)
+		resp, err := http.Get("http://" + addr)
+		if err != nil {
+			continue
+		}
+		b, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if pid := string(b); pid != skip {
+			return pid
+		}
+	}
+	return ""
+}
+
+func TestPrefork(t *testing.T) {
+	addr := freeAddr(t)
+
+	var children []*exec.Cmd
+	oldCommand := preforkCommand
+	preforkCommand = func() (*exec.Cmd, error) {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestPreforkHelperProcess$")
+		cmd.Env = append(os.Environ(), "HIME_TEST_PREFORK_ADDR="+addr)
+		children = append(children, cmd)
+		return cmd, nil
+	}
+	defer func() { preforkCommand = oldCommand }()
+
+	app := New().Address(addr).Prefork(1)
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- app.ListenAndServe() }()
+
+	pid := waitPreforkPid(addr, "")
+	require.NotEmpty(t, pid)
+	assert.NotEqual(t, strconv.Itoa(os.Getpid()), pid)
+
+	t.Run("Restart crashed child", func(t *testing.T) {
+		p, _ := strconv.Atoi(pid)
+		require.NoError(t, syscall.Kill(p, syscall.SIGKILL))
+
+		newPid := waitPreforkPid(addr, pid)
+		assert.NotEmpty(t, newPid)
+	})
+
+	t.Run("Forward SIGTERM", func(t *testing.T) {
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+
+		select {
+		case err := <-errChan:
+			assert.NoError(t, err)
+		case <-time.After(10 * time.Second):
+			require.FailNow(t, "prefork not stopped")
+		}
+		if assert.Len(t, children, 2) {
+			assert.NotNil(t, children[0].ProcessState)
+			if assert.NotNil(t, children[1].ProcessState) {
+				assert.True(t, children[1].ProcessState.Success(), "child must shutdown gracefully")
+			}
+		}
+	})
+}
+
+func TestPreforkParentKilled(t *testing.T) {
+	addr := freeAddr(t)
+
+	parent := exec.Command(os.Args[0], "-test.run=^TestPreforkParentHelperProcess$")
+	parent.Env = append(os.Environ(), "HIME_TEST_PREFORK_PARENT_ADDR="+addr)
+	require.NoError(t, parent.Start())
+	defer parent.Process.Kill()
+
+	require.NotEmpty(t, waitPreforkPid(addr, ""))
+
+	require.NoError(t, parent.Process.Kill())
+	parent.Wait()
+
+	for i := 0; i < 50; i++ {
+		time.Sleep(100 * time.Millisecond)
+		if _, err := http.Get("http://" + addr); err != nil {
+			return
+		}
+	}
+	assert.Fail(t, "child still serving after parent killed")
+}
+
+func TestPreforkConfig(t *testing.T) {
+	app := New().ParseConfig([]byte(`
+server:
+  prefork: 4`))
+	assert.Equal(t, 4, app.prefork)
+	assert.Equal(t, 4, app.Clone().prefork)
+}
@@ -0,0 +1,199 @@
+package hime
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// isQueryStruct reports whether p is struct (or pointer to struct)
+// that should encode into query params,
+// only struct that has query tag (in itself or embedded struct) is,
+// struct that can marshal itself into text (e.g. time.Time) is not
+func isQueryStruct(p interface{}) bool {
+	return isQueryStructType(reflect.TypeOf(p))
+}
+
+func isQueryStructType(t reflect.Type) bool {
+	if !isStructValueType(t) {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return hasQueryTag(t, make(map[reflect.Type]bool))
+}
+
+// isStructValueType reports whether t is struct (or pointer to struct)
+// that can not marshal itself into text
+func isStructValueType(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	pt := reflect.PtrTo(t)
+	return !pt.Implements(textMarshalerType) && !pt.Implements(stringerType)
+}
+
+// hasQueryTag reports whether struct t or its embedded structs has any query tag
+func hasQueryTag(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if _, ok := f.Tag.Lookup("query"); ok {
+			return true
+		}
+		if f.Anonymous && isStructValueType(f.Type) {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if hasQueryTag(ft, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mergeValueWithStruct encodes struct's fields into s,
+// field's name comes from query tag or field name
+//
+//	type Filter struct {
+//		Query string    `query:"q,omitempty"`
+//		Tags  []string  `query:"tag"`
+//		Since time.Time `query:"since,omitempty"`
+//		Page  *int      `query:"page"`
+//		Debug bool      `query:"-"`
+//	}
+func mergeValueWithStruct(s url.Values, p interface{}) {
+	v := reflect.ValueOf(p)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	encodeStruct(s, v)
+}
+
+func encodeStruct(s url.Values, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := v.Field(i)
+
+		tag := f.Tag.Get("query")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if j := strings.IndexByte(tag, ','); j >= 0 {
+			name, opts = tag[:j], tag[j+1:]
+		}
+		omitEmpty := false
+		for _, opt := range strings.Split(opts, ",") {
+			if opt == "omitempty" {
+				omitEmpty = true
+			}
+		}
+
+		// flatten embedded struct without name
+		if f.Anonymous && name == "" {
+			ev := fv
+			if ev.Kind() == reflect.Ptr {
+				if ev.IsNil() {
+					continue
+				}
+				ev = ev.Elem()
+			}
+			if isStructValueType(ev.Type()) {
+				encodeStruct(s, ev)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		if omitEmpty && isEmpty(fv.Interface()) {
+			continue
+		}
+
+		switch fv.Kind() {
+		case reflect.Slice, reflect.Array:
+			if fv.Type().Implements(textMarshalerType) {
+				break
+			}
+			for j := 0; j < fv.Len(); j++ {
+				if x, ok := encodeQueryValue(fv.Index(j)); ok {
+					s[name] = append(s[name], x)
+				}
+			}
+			continue
+		}
+
+		if x, ok := encodeQueryValue(fv); ok {
+			s[name] = append(s[name], x)
+		}
+	}
+}
+
+// encodeQueryValue encodes v into string, returns false for nil value
+func encodeQueryValue(v reflect.Value) (string, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", false
+		}
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+			return marshalText(m), true
+		}
+		v = v.Elem()
+	}
+
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		return marshalText(m), true
+	}
+	if reflect.PtrTo(v.Type()).Implements(textMarshalerType) {
+		x := reflect.New(v.Type())
+		x.Elem().Set(v)
+		return marshalText(x.Interface().(encoding.TextMarshaler)), true
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.String:
+		return v.String(), true
+	}
+	return fmt.Sprint(v.Interface()), true
+}
+
+func marshalText(m encoding.TextMarshaler) string {
+	b, err := m.MarshalText()
+	if err != nil {
+		panicf("can not marshal query value; %v", err)
+	}
+	return string(b)
+}
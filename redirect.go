@@ -0,0 +1,171 @@
+package hime
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// RedirectPolicy is the open redirect protection configure
+type RedirectPolicy struct {
+	hosts        []string
+	schemes      []string
+	validateBack bool
+}
+
+// RedirectPolicy returns app's redirect policy
+func (app *App) RedirectPolicy() *RedirectPolicy {
+	if app.redirectPolicy == nil {
+		app.redirectPolicy = &RedirectPolicy{}
+	}
+
+	return app.redirectPolicy
+}
+
+// AllowHosts allows safe redirect to absolute url with given hosts,
+// host can start with "*." to allow all subdomains,
+// host without port matches any port
+func (p *RedirectPolicy) AllowHosts(hosts ...string) *RedirectPolicy {
+	for _, h := range hosts {
+		p.hosts = append(p.hosts, strings.ToLower(h))
+	}
+	return p
+}
+
+// AllowSchemes sets allowed schemes for absolute url
+//
+// default is http and https
+func (p *RedirectPolicy) AllowSchemes(schemes ...string) *RedirectPolicy {
+	for _, s := range schemes {
+		p.schemes = append(p.schemes, strings.ToLower(s))
+	}
+	return p
+}
+
+// ValidateBack validates referer in ctx.RedirectBack,
+// referer that is not same host as request and not allowed by policy
+// is replaced with fallback
+func (p *RedirectPolicy) ValidateBack(enable bool) *RedirectPolicy {
+	p.validateBack = enable
+	return p
+}
+
+// Allowed reports whether u is relative url or absolute url that allowed by policy
+func (p *RedirectPolicy) Allowed(u string) bool {
+	l, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	if l.Scheme == "" && l.Host == "" {
+		return !strings.HasPrefix(u, "//") && !strings.HasPrefix(u, `/\`)
+	}
+	return p.allowedAbsolute(u)
+}
+
+// allowedAbsolute reports whether u is absolute url that allowed by policy
+func (p *RedirectPolicy) allowedAbsolute(u string) bool {
+	if p == nil || len(p.hosts) == 0 {
+		return false
+	}
+	l, err := url.Parse(u)
+	if err != nil || l.Scheme == "" {
+		return false
+	}
+	return p.allowedScheme(l.Scheme) && p.allowedHost(l)
+}
+
+// allowedReferer reports whether referer is same host as request or allowed by policy
+func (ctx *Context) allowedReferer(u string) bool {
+	l, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	if l.Scheme != "" && strings.EqualFold(l.Host, ctx.Host) && ctx.app.redirectPolicy.allowedScheme(l.Scheme) {
+		return true
+	}
+	return ctx.app.redirectPolicy.Allowed(u)
+}
+
+func (p *RedirectPolicy) allowedScheme(scheme string) bool {
+	scheme = strings.ToLower(scheme)
+	if len(p.schemes) == 0 {
+		return scheme == "http" || scheme == "https"
+	}
+	for _, s := range p.schemes {
+		if s == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RedirectPolicy) allowedHost(l *url.URL) bool {
+	for _, h := range p.hosts {
+		host := l.Hostname()
+		if strings.Contains(h, ":") {
+			host = l.Host
+		}
+		host = strings.ToLower(host)
+
+		if strings.HasPrefix(h, "*.") {
+			if strings.HasSuffix(host, h[1:]) {
+				return true
+			}
+			continue
+		}
+		if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+type redirectPolicyConfig struct {
+	Hosts        []string `yaml:"hosts" json:"hosts"`
+	Schemes      []string `yaml:"schemes" json:"schemes"`
+	ValidateBack bool     `yaml:"validateBack" json:"validateBack"`
+}
+
+func (x *redirectPolicyConfig) store(p *RedirectPolicy) {
+	p.AllowHosts(x.Hosts...)
+	p.AllowSchemes(x.Schemes...)
+	p.ValidateBack(x.ValidateBack)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (p *RedirectPolicy) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var x redirectPolicyConfig
+	err := unmarshal(&x)
+	if err != nil {
+		return err
+	}
+
+	x.store(p)
+
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (p *RedirectPolicy) UnmarshalJSON(b []byte) error {
+	var x redirectPolicyConfig
+	err := json.Unmarshal(b, &x)
+	if err != nil {
+		return err
+	}
+
+	x.store(p)
+
+	return nil
+}
+
+func cloneRedirectPolicy(p *RedirectPolicy) *RedirectPolicy {
+	if p == nil {
+		return nil
+	}
+
+	return &RedirectPolicy{
+		hosts:        append([]string(nil), p.hosts...),
+		schemes:      append([]string(nil), p.schemes...),
+		validateBack: p.validateBack,
+	}
+}
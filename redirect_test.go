@@ -0,0 +1,129 @@
+package hime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedirectPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Allowed", func(t *testing.T) {
+		p := New().RedirectPolicy().AllowHosts("sso.example.com", "*.example.org", "localhost:8080")
+
+		cases := []struct {
+			URL     string
+			Allowed bool
+		}{
+			{"/path", true},
+			{"path?a=1", true},
+			{"//evil.com/path", false},
+			{`/\evil.com/path`, false},
+			{"https://sso.example.com/login", true},
+			{"http://SSO.example.com:8443/login", true},
+			{"https://evil.com/login", false},
+			{"https://sso.example.com.evil.com/login", false},
+			{"https://a.example.org/", true},
+			{"https://example.org/", false},
+			{"https://evilexample.org/", false},
+			{"http://localhost:8080/", true},
+			{"http://localhost:9000/", false},
+			{"javascript://sso.example.com/%0aalert(1)", false},
+		}
+		for _, c := range cases {
+			assert.Equal(t, c.Allowed, p.Allowed(c.URL), c.URL)
+		}
+	})
+
+	t.Run("Allowed schemes", func(t *testing.T) {
+		p := New().RedirectPolicy().AllowHosts("example.com").AllowSchemes("https", "myapp")
+		assert.True(t, p.Allowed("https://example.com/"))
+		assert.True(t, p.Allowed("myapp://example.com/"))
+		assert.False(t, p.Allowed("http://example.com/"))
+	})
+
+	t.Run("Nil policy", func(t *testing.T) {
+		var p *RedirectPolicy
+		assert.True(t, p.Allowed("/path"))
+		assert.False(t, p.Allowed("https://example.com/"))
+	})
+
+	redirect := func(app *App, referer string, fn func(ctx *Context) error) string {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "http://localhost/path1", nil)
+		if referer != "" {
+			r.Header.Set("Referer", referer)
+		}
+		assert.NoError(t, fn(NewAppContext(app, w, r)))
+		return w.Header().Get("Location")
+	}
+
+	t.Run("SafeRedirect", func(t *testing.T) {
+		app := New()
+		app.RedirectPolicy().AllowHosts("sso.example.com")
+
+		safeRedirect := func(u string, params ...interface{}) string {
+			return redirect(app, "", func(ctx *Context) error { return ctx.SafeRedirect(u, params...) })
+		}
+		assert.Equal(t, "https://sso.example.com/login?next=%2F", safeRedirect("https://sso.example.com/login", &Param{"next", "/"}))
+		assert.Equal(t, "/login", safeRedirect("https://evil.com/login"))
+		assert.Equal(t, "/login", safeRedirect("/login"))
+	})
+
+	t.Run("SafeRedirectBack", func(t *testing.T) {
+		app := New()
+		app.RedirectPolicy().AllowHosts("sso.example.com")
+
+		safeRedirectBack := func(referer string) string {
+			return redirect(app, referer, func(ctx *Context) error { return ctx.SafeRedirectBack("/fallback") })
+		}
+		assert.Equal(t, "https://sso.example.com/login", safeRedirectBack("https://sso.example.com/login"))
+		assert.Equal(t, "/login", safeRedirectBack("https://evil.com/login"))
+		assert.Equal(t, "/fallback", safeRedirectBack(""))
+	})
+
+	t.Run("RedirectBack without validate", func(t *testing.T) {
+		app := New()
+		app.RedirectPolicy().AllowHosts("sso.example.com")
+
+		assert.Equal(t, "https://evil.com/login", redirect(app, "https://evil.com/login", func(ctx *Context) error {
+			return ctx.RedirectBack("/fallback")
+		}))
+	})
+
+	t.Run("RedirectBack with validate", func(t *testing.T) {
+		app := New()
+		app.RedirectPolicy().AllowHosts("sso.example.com").ValidateBack(true)
+
+		redirectBack := func(referer string) string {
+			return redirect(app, referer, func(ctx *Context) error { return ctx.RedirectBack("/fallback") })
+		}
+		assert.Equal(t, "http://localhost/path2", redirectBack("http://localhost/path2"))
+		assert.Equal(t, "https://sso.example.com/login", redirectBack("https://sso.example.com/login"))
+		assert.Equal(t, "/fallback", redirectBack("https://evil.com/login"))
+		assert.Equal(t, "/fallback", redirectBack("javascript:alert(1)"))
+		assert.Equal(t, "/fallback", redirectBack(""))
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		app := New().ParseConfig([]byte(`
+redirectPolicy:
+  hosts: [SSO.example.com]
+  schemes: [https]
+  validateBack: true`))
+
+		p := app.redirectPolicy
+		if assert.NotNil(t, p) {
+			assert.Equal(t, []string{"sso.example.com"}, p.hosts)
+			assert.Equal(t, []string{"https"}, p.schemes)
+			assert.True(t, p.validateBack)
+		}
+
+		x := app.Clone().redirectPolicy
+		assert.Equal(t, p, x)
+		assert.False(t, p == x)
+	})
+}
@@ -0,0 +1,118 @@
+package hime
+
+import (
+	"bytes"
+	"log"
+	"time"
+)
+
+// RenderStats is the stats of a view render
+type RenderStats struct {
+	Name        string
+	ExecuteTime time.Duration
+	MinifyTime  time.Duration
+	Size        int
+}
+
+// Duration returns total render time
+func (st RenderStats) Duration() time.Duration {
+	return st.ExecuteTime + st.MinifyTime
+}
+
+// RenderObserver observes view renders from ctx.View
+type RenderObserver interface {
+	ObserveRender(ctx *Context, st RenderStats)
+}
+
+// RenderObserverFunc is the function adapter for RenderObserver
+type RenderObserverFunc func(ctx *Context, st RenderStats)
+
+// ObserveRender implements RenderObserver
+func (f RenderObserverFunc) ObserveRender(ctx *Context, st RenderStats) {
+	f(ctx, st)
+}
+
+// RenderObserver registers render observers
+func (app *App) RenderObserver(observers ...RenderObserver) *App {
+	app.renderObservers = append(app.renderObservers, observers...)
+	return app
+}
+
+// SlowRender logs renders that take longer than threshold,
+// set to 0 to disable
+//
+// default is 0
+func (app *App) SlowRender(threshold time.Duration) *App {
+	app.slowRender = threshold
+	return app
+}
+
+// statsViewEngine is the view engine that can record render stats
+type statsViewEngine interface {
+	executeViewStats(ctx *Context, w *bytes.Buffer, name string, data interface{}, st *RenderStats) error
+}
+
+func (app *App) executeView(ctx *Context, e ViewEngine, w *bytes.Buffer, name string, data interface{}) error {
+	m := app.getViewMinifier(e)
+
+	if len(app.renderObservers) == 0 && app.slowRender <= 0 {
+		err := e.ExecuteView(ctx, w, name, data)
+		if err != nil || m == nil {
+			return err
+		}
+		return minifyBuffer(m, w)
+	}
+
+	st := RenderStats{Name: name}
+
+	var err error
+	if se, ok := e.(statsViewEngine); ok {
+		err = se.executeViewStats(ctx, w, name, data, &st)
+	} else {
+		start := time.Now()
+		err = e.ExecuteView(ctx, w, name, data)
+		st.ExecuteTime = time.Since(start)
+	}
+	if err != nil {
+		return err
+	}
+
+	if m != nil {
+		start := time.Now()
+		err = minifyBuffer(m, w)
+		st.MinifyTime = time.Since(start)
+		if err != nil {
+			return err
+		}
+	}
+
+	st.Size = w.Len()
+	app.observeRender(ctx, st)
+
+	return nil
+}
+
+func (app *App) observeRender(ctx *Context, st RenderStats) {
+	for _, o := range app.renderObservers {
+		o.ObserveRender(ctx, st)
+	}
+
+	if app.slowRender > 0 && st.Duration() >= app.slowRender {
+		logf := log.Printf
+		if app.srv.ErrorLog != nil {
+			logf = app.srv.ErrorLog.Printf
+		}
+		logf("hime: slow render '%s' took %v (execute %v, minify %v, size %d bytes)",
+			st.Name, st.Duration(), st.ExecuteTime, st.MinifyTime, st.Size)
+	}
+}
+
+func cloneRenderObservers(xs []RenderObserver) []RenderObserver {
+	if xs == nil {
+		return nil
+	}
+
+	rs := make([]RenderObserver, len(xs))
+	copy(rs, xs)
+	return rs
+}
@@ -0,0 +1,94 @@
+package hime
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderObserver(t *testing.T) {
+	t.Parallel()
+
+	t.Run("template", func(t *testing.T) {
+		app := New()
+		app.Template().Minify().Parse("index", `  <p>  {{.}}  </p>  `)
+
+		var stats []RenderStats
+		app.RenderObserver(RenderObserverFunc(func(ctx *Context, st RenderStats) {
+			stats = append(stats, st)
+		}))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		assert.NoError(t, NewAppContext(app, w, r).View("index", "hime"))
+
+		if assert.Len(t, stats, 1) {
+			assert.Equal(t, "index", stats[0].Name)
+			assert.Equal(t, len("<p>hime"), stats[0].Size)
+			assert.NotZero(t, stats[0].ExecuteTime)
+			assert.NotZero(t, stats[0].MinifyTime)
+			assert.Equal(t, stats[0].ExecuteTime+stats[0].MinifyTime, stats[0].Duration())
+		}
+	})
+
+	t.Run("view engine", func(t *testing.T) {
+		app := New()
+		app.ViewEngine(MinifyViewEngine(templateEngine{New().Template().Parse("index", `<p>a</p>`).app}))
+
+		var stats []RenderStats
+		app.RenderObserver(RenderObserverFunc(func(ctx *Context, st RenderStats) {
+			stats = append(stats, st)
+		}))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		assert.NoError(t, NewAppContext(app, w, r).View("index", nil))
+
+		if assert.Len(t, stats, 1) {
+			assert.Equal(t, "index", stats[0].Name)
+			assert.Equal(t, len("<p>a"), stats[0].Size)
+			assert.Zero(t, stats[0].MinifyTime)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		app := New()
+		app.Template().Parse("index", `{{.A.B}}`)
+
+		called := false
+		app.RenderObserver(RenderObserverFunc(func(ctx *Context, st RenderStats) {
+			called = true
+		}))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		assert.Error(t, NewAppContext(app, w, r).View("index", map[string]string{"A": "a"}))
+		assert.False(t, called)
+	})
+
+	t.Run("SlowRender", func(t *testing.T) {
+		var b bytes.Buffer
+		app := New()
+		app.Server().ErrorLog = log.New(&b, "", 0)
+		app.SlowRender(time.Millisecond)
+		app.Template().Func("sleep", func() string {
+			time.Sleep(2 * time.Millisecond)
+			return ""
+		}).Parse("index", `{{sleep}}ok`)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		assert.NoError(t, NewAppContext(app, w, r).View("index", nil))
+		assert.Contains(t, b.String(), "hime: slow render 'index' took")
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		app := New().ParseConfig([]byte(`slowRender: 200ms`))
+		assert.Equal(t, 200*time.Millisecond, app.slowRender)
+	})
+}
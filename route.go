@@ -1,8 +1,18 @@
 package hime
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
 
-// Routes is the map for route name => path
+// Routes is the map for route name => path,
+// path can contain named params in {name} form
+//
+// Example:
+//
+//	user: /users/{id}/posts/{slug}
 type Routes map[string]string
 
 func cloneRoutes(xs Routes) Routes {
@@ -27,7 +37,10 @@ func (app *App) Routes(routes Routes) *App {
 	return app
 }
 
-// Route gets route path from given name
+// Route gets route path from given name,
+// route's named params are filled from *Param (or Param) with the same name,
+// then from other path params in order,
+// leftover params are appended to path as usual
 func (app *App) Route(name string, params ...interface{}) string {
 	if app.routes == nil {
 		panic(newErrRouteNotFound(name))
@@ -36,7 +49,123 @@ func (app *App) Route(name string, params ...interface{}) string {
 	if !ok {
 		panic(newErrRouteNotFound(name))
 	}
-	return buildPath(path, params...)
+	path, params, err := fillRouteParams(name, path, params)
+	if err != nil {
+		panic(err)
+	}
+	return app.basePath + buildPath(path, params...)
+}
+
+// RoutePattern gets registered route path from given name
+// without fill any params and base path
+func (app *App) RoutePattern(name string) string {
+	path, ok := app.routes[name]
+	if !ok {
+		panic(newErrRouteNotFound(name))
+	}
+	return path
+}
+
+type ctxKeyPathParams struct{}
+
+// WithPathParams returns new context with given path params,
+// used by router to pass matched path params to handler
+func WithPathParams(ctx context.Context, params map[string]string) context.Context {
+	return context.WithValue(ctx, ctxKeyPathParams{}, params)
+}
+
+// PathParam returns path param from context
+func PathParam(ctx context.Context, name string) string {
+	params, _ := ctx.Value(ctxKeyPathParams{}).(map[string]string)
+	return params[name]
+}
+
+// PathParam returns path param that matched by router
+func (ctx *Context) PathParam(name string) string {
+	return PathParam(ctx, name)
+}
+
+// routeParamNames returns named params in path
+func routeParamNames(path string) []string {
+	var names []string
+	for {
+		i := strings.IndexByte(path, '{')
+		if i < 0 {
+			return names
+		}
+		j := strings.IndexByte(path[i:], '}')
+		if j < 0 {
+			return names
+		}
+		names = append(names, path[i+1:i+j])
+		path = path[i+j+1:]
+	}
+}
+
+func isPathParam(p interface{}) bool {
+	switch p.(type) {
+	case url.Values, map[string]string, map[string]interface{}, *Param, Param:
+		return false
+	}
+	return !isQueryStruct(p)
+}
+
+// fillRouteParams replaces named params in path with values from params,
+// returns filled path and leftover params
+func fillRouteParams(route, path string, params []interface{}) (string, []interface{}, error) {
+	names := routeParamNames(path)
+	if len(names) == 0 {
+		return path, params, nil
+	}
+
+	values := make(map[string]string, len(names))
+	isName := make(map[string]bool, len(names))
+	for _, name := range names {
+		isName[name] = true
+	}
+
+	var rest, positional []interface{}
+	for _, p := range params {
+		var x *Param
+		switch v := p.(type) {
+		case *Param:
+			x = v
+		case Param:
+			x = &v
+		}
+
+		if x != nil && isName[x.Name] {
+			if _, ok := values[x.Name]; ok {
+				return "", nil, &ErrRouteParamExtra{Route: route, Param: x.Name}
+			}
+			values[x.Name] = fmt.Sprint(x.Value)
+			continue
+		}
+		if isPathParam(p) {
+			positional = append(positional, p)
+			continue
+		}
+		rest = append(rest, p)
+	}
+
+	for _, name := range names {
+		if _, ok := values[name]; ok {
+			continue
+		}
+		if len(positional) == 0 {
+			return "", nil, &ErrRouteParamMissing{Route: route, Param: name}
+		}
+		values[name] = strings.TrimPrefix(fmt.Sprint(positional[0]), "/")
+		positional = positional[1:]
+	}
+	if len(positional) > 0 {
+		return "", nil, &ErrRouteParamExtra{Route: route, Param: fmt.Sprint(positional[0])}
+	}
+
+	for _, name := range names {
+		path = strings.Replace(path, "{"+name+"}", url.PathEscape(values[name]), -1)
+	}
+	return path, rest, nil
 }
 
 // Route gets route path from name
@@ -1,6 +1,7 @@
 package hime
 
 import (
+	"bytes"
 	"net/http/httptest"
 	"testing"
 
@@ -59,6 +60,84 @@ func TestRoute(t *testing.T) {
 		assert.Panics(t, func() { app.Route("c") })
 	})
 
+	t.Run("named params", func(t *testing.T) {
+		app := New()
+		app.Routes(Routes{
+			"user": "/users/{id}/posts/{slug}",
+			"file": "/files/{name}",
+		})
+
+		cases := []struct {
+			Route  string
+			Params []interface{}
+			Output string
+		}{
+			{"user", []interface{}{Param{"id", 5}, Param{"slug", "hello"}}, "/users/5/posts/hello"},
+			{"user", []interface{}{&Param{"slug", "hello"}, &Param{"id", 5}}, "/users/5/posts/hello"},
+			{"user", []interface{}{5, "hello"}, "/users/5/posts/hello"},
+			{"user", []interface{}{Param{"slug", "hello"}, 5}, "/users/5/posts/hello"},
+			{"user", []interface{}{Param{"id", 5}, Param{"slug", "a"}, Param{"page", 2}}, "/users/5/posts/a?page=2"},
+			{"user", []interface{}{5, "a", map[string]string{"q": "x"}}, "/users/5/posts/a?q=x"},
+			{"file", []interface{}{Param{"name", "a b/c"}}, "/files/a%20b%2Fc"},
+		}
+
+		for _, c := range cases {
+			assert.Equal(t, c.Output, app.Route(c.Route, c.Params...))
+		}
+	})
+
+	t.Run("named params missing", func(t *testing.T) {
+		app := New()
+		app.Routes(Routes{"user": "/users/{id}/posts/{slug}"})
+
+		assert.PanicsWithError(t, "hime: route 'user' missing param 'slug'", func() { app.Route("user", 5) })
+		assert.PanicsWithError(t, "hime: route 'user' missing param 'id'", func() { app.Route("user") })
+	})
+
+	t.Run("named params extra", func(t *testing.T) {
+		app := New()
+		app.Routes(Routes{"user": "/users/{id}"})
+
+		assert.PanicsWithError(t, "hime: route 'user' extra param 'a'", func() { app.Route("user", 5, "a") })
+		assert.PanicsWithError(t, "hime: route 'user' extra param 'id'", func() { app.Route("user", Param{"id", 1}, Param{"id", 2}) })
+	})
+
+	t.Run("named params in template", func(t *testing.T) {
+		app := New()
+		app.ParseConfig([]byte(`
+routes:
+  user: /users/{id}`))
+		tp := app.Template()
+		tp.Parse("t", `<a href="{{route "user" (param "id" 1) (param "tab" "posts")}}">user</a>`)
+		tp.Parse("invalid", `{{route "user"}}`)
+
+		b := bytes.Buffer{}
+		assert.NoError(t, tp.list["t"].Execute(&b, nil))
+		assert.Equal(t, `<a href="/users/1?tab=posts">user</a>`, b.String())
+
+		assert.Error(t, tp.list["invalid"].Execute(&b, nil))
+	})
+
+	t.Run("query struct", func(t *testing.T) {
+		type filter struct {
+			Query string   `query:"q,omitempty"`
+			Tags  []string `query:"tag"`
+		}
+
+		app := New()
+		app.Routes(Routes{"posts": "/users/{id}/posts"})
+		assert.Equal(t, "/users/1/posts?q=go&tag=a&tag=b", app.Route("posts", filter{"go", []string{"a", "b"}}, 1))
+
+		app.Template().Parse("t", `{{route "posts" 1 .}}`)
+		b := bytes.Buffer{}
+		assert.NoError(t, app.template["t"].Execute(&b, &filter{Tags: []string{"x"}}))
+		assert.Equal(t, "/users/1/posts?tag=x", b.String())
+
+		// struct without query tag is path param
+		type untagged struct{ ID int }
+		assert.Equal(t, "/users/%7B1%7D/posts", app.Route("posts", untagged{1}))
+	})
+
 	t.Run("Context", func(t *testing.T) {
 		t.Run("retrieve route from context", func(t *testing.T) {
 			app := New()
@@ -0,0 +1,198 @@
+package hime
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"text/template"
+)
+
+// RoutesJSON returns app's routes with base path as json object,
+// named params are kept as {name} placeholders
+func (app *App) RoutesJSON() []byte {
+	rs := make(map[string]string, len(app.routes))
+	for name, path := range app.routes {
+		if path == "" {
+			path = "/"
+		}
+		rs[name] = app.basePath + path
+	}
+
+	// map[string]string always marshal success
+	b, _ := json.Marshal(rs)
+	return b
+}
+
+// RoutesJS returns javascript module that exports app's routes
+// and route(name, ...params) helper that builds path same as app.Route
+//
+//	import { route } from "/routes.js"
+//	route("user", 1, { tab: "posts" }) // => /users/1?tab=posts
+func (app *App) RoutesJS() []byte {
+	return app.routesModule(false)
+}
+
+// RoutesTS returns typescript module that exports app's routes
+// and typed route(name, ...params) helper
+func (app *App) RoutesTS() []byte {
+	return app.routesModule(true)
+}
+
+func (app *App) routesModule(ts bool) []byte {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by hime. DO NOT EDIT.\n\n")
+	b.WriteString("export const routes = ")
+	b.Write(app.RoutesJSON())
+	if ts {
+		b.WriteString(" as const;\n")
+		b.WriteString(routesTSHelper)
+	} else {
+		b.WriteString(";\n")
+		b.WriteString(routesJSHelper)
+	}
+	return b.Bytes()
+}
+
+// RoutesHandler returns handler that serves app's routes,
+// serves typescript module if request path ends with .ts,
+// javascript module if ends with .js,
+// or json
+func (app *App) RoutesHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			b           []byte
+			contentType string
+		)
+		switch {
+		case strings.HasSuffix(r.URL.Path, ".ts"):
+			b, contentType = app.RoutesTS(), "application/typescript; charset=utf-8"
+		case strings.HasSuffix(r.URL.Path, ".js"):
+			b, contentType = app.RoutesJS(), "text/javascript; charset=utf-8"
+		default:
+			b, contentType = app.RoutesJSON(), "application/json; charset=utf-8"
+		}
+
+		et := etag(b)
+		w.Header().Set("ETag", et)
+		if matchETag(r, et) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Write(b)
+	})
+}
+
+// routesHelper is the route helper module source,
+// {{t "..."}} is type annotation that only typescript keeps
+const routesHelper = `
+{{- if ts}}
+export type RouteName = keyof typeof routes;
+
+export type RouteParam = string | number | boolean | { [name: string]: unknown };
+{{end}}
+function escape(s{{t ": string"}}){{t ": string"}} {
+	return encodeURIComponent(s).replace(/[!'()*]/g, (c) => "%" + c.charCodeAt(0).toString(16).toUpperCase());
+}
+
+function pathEscape(s{{t ": string"}}){{t ": string"}} {
+	return escape(s).replace(/%(24|26|2B|3A|3D|40)/g, (c) => decodeURIComponent(c));
+}
+
+function queryEscape(s{{t ": string"}}){{t ": string"}} {
+	return escape(s).replace(/%20/g, "+");
+}
+
+function cleanPath(p{{t ": string"}}){{t ": string"}} {
+	const rooted = p.startsWith("/");
+	const xs{{t ": string[]"}} = [];
+	for (const x of p.split("/")) {
+		if (x === "" || x === ".") continue;
+		if (x === "..") {
+			if (xs.length > 0 && xs[xs.length - 1] !== "..") xs.pop();
+			else if (!rooted) xs.push("..");
+			continue;
+		}
+		xs.push(x);
+	}
+	return (rooted ? "/" : "") + xs.join("/") || (rooted ? "/" : ".");
+}
+
+function isObject(p{{t ": unknown"}}){{t ": p is { [name: string]: unknown }"}} {
+	return p !== null && typeof p === "object" && !Array.isArray(p);
+}
+
+export function route(name{{t ": RouteName"}}, ...params{{t ": RouteParam[]"}}){{t ": string"}} {
+	let path{{t ": string"}} = routes[name];
+	if (path === undefined) throw new Error("hime: route '" + name + "' not found");
+
+	const names{{t ": string[]"}} = [];
+	path.replace(/\{([^}]*)\}/g, (_, x) => String(names.push(x)));
+
+	const values{{t ": { [name: string]: string }"}} = {};
+	const query{{t ": { [name: string]: unknown[] }"}} = {};
+	const segments{{t ": unknown[]"}} = [];
+	for (const p of params) {
+		if (!isObject(p)) {
+			segments.push(p);
+			continue;
+		}
+		for (const [k, v] of Object.entries(p)) {
+			if (names.includes(k)) {
+				if (k in values) throw new Error("hime: route '" + name + "' extra param '" + k + "'");
+				values[k] = String(v);
+				continue;
+			}
+			if (v === undefined || v === null) continue;
+			query[k] = (query[k] || []).concat(v);
+		}
+	}
+
+	for (const x of names) {
+		if (x in values) continue;
+		if (segments.length === 0) throw new Error("hime: route '" + name + "' missing param '" + x + "'");
+		values[x] = String(segments.shift()).replace(/^\//, "");
+	}
+	if (names.length > 0 && segments.length > 0) {
+		throw new Error("hime: route '" + name + "' extra param '" + String(segments[0]) + "'");
+	}
+	for (const x of names) {
+		path = path.split("{" + x + "}").join(pathEscape(values[x]));
+	}
+
+	const xs = segments.map((x) => String(x).replace(/^\//, "")).filter((x) => x !== "");
+	if (path === "" || (segments.length > 0 && !path.endsWith("/"))) path += "/";
+	if (xs.length > 0) path += cleanPath(xs.join("/"));
+
+	const qs = Object.keys(query).sort()
+		.map((k) => query[k].map((v) => queryEscape(k) + "=" + queryEscape(String(v))).join("&"))
+		.join("&");
+	return qs ? path + "?" + qs : path;
+}
+`
+
+var (
+	routesJSHelper = renderRoutesHelper(false)
+	routesTSHelper = renderRoutesHelper(true)
+)
+
+// renderRoutesHelper renders route helper as typescript if ts is true,
+// or javascript by strips type annotations
+func renderRoutesHelper(ts bool) string {
+	t := template.Must(template.New("").Funcs(template.FuncMap{
+		"ts": func() bool { return ts },
+		"t": func(s string) string {
+			if ts {
+				return s
+			}
+			return ""
+		},
+	}).Parse(routesHelper))
+
+	var b strings.Builder
+	// helper does not use data, execute always success
+	t.Execute(&b, nil)
+	return b.String()
+}
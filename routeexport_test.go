@@ -0,0 +1,155 @@
+package hime
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutesExport(t *testing.T) {
+	t.Parallel()
+
+	newApp := func() *App {
+		return New().Routes(Routes{
+			"index": "/",
+			"user":  "/users/{id}",
+		})
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		assert.JSONEq(t, `{"index":"/","user":"/users/{id}"}`, string(newApp().RoutesJSON()))
+		assert.Equal(t, `{}`, string(New().RoutesJSON()))
+	})
+
+	t.Run("JSON with base path", func(t *testing.T) {
+		app := newApp().BasePath("/admin")
+		assert.JSONEq(t, `{"index":"/admin/","user":"/admin/users/{id}"}`, string(app.RoutesJSON()))
+	})
+
+	t.Run("JS", func(t *testing.T) {
+		b := string(newApp().RoutesJS())
+		assert.Contains(t, b, `export const routes = {"index":"/","user":"/users/{id}"};`)
+		assert.Contains(t, b, "export function route(name, ...params)")
+	})
+
+	t.Run("TS", func(t *testing.T) {
+		b := string(newApp().RoutesTS())
+		assert.Contains(t, b, `export const routes = {"index":"/","user":"/users/{id}"} as const;`)
+		assert.Contains(t, b, "export function route(name: RouteName, ...params: RouteParam[]): string")
+	})
+
+	t.Run("TS golden", func(t *testing.T) {
+		b, err := ioutil.ReadFile("testdata/routes.ts")
+		require.NoError(t, err)
+		assert.Equal(t, string(b), string(newApp().RoutesTS()))
+	})
+
+	t.Run("JS same as Route", func(t *testing.T) {
+		node, err := exec.LookPath("node")
+		if err != nil {
+			t.Skip("node not found")
+		}
+
+		app := New().BasePath("/admin").Routes(Routes{
+			"index": "/",
+			"user":  "/users/{id}",
+			"post":  "/users/{id}/posts/{slug}",
+		})
+
+		cases := []struct {
+			Name string
+			Go   []interface{}
+			JS   string
+		}{
+			{"index", nil, `[]`},
+			{"index", []interface{}{"a", "/b", "../c"}, `["a", "/b", "../c"]`},
+			{"user", []interface{}{1}, `[1]`},
+			{"user", []interface{}{"/1", "edit"}, `["/1", "edit"]`},
+			{"user", []interface{}{&Param{Name: "id", Value: "a b"}}, `[{"id": "a b"}]`},
+			{"user", []interface{}{"a/b,c;d$&+:=@!'()*"}, `["a/b,c;d$&+:=@!'()*"]`},
+			{"user", []interface{}{1, url.Values{"tab": {"posts"}, "q": {"a b", "c&d"}}}, `[1, {"tab": "posts", "q": ["a b", "c&d"]}]`},
+			{"post", []interface{}{&Param{Name: "slug", Value: "hi"}, 2}, `[{"slug": "hi"}, 2]`},
+			{"user", nil, `[]`},
+			{"user", []interface{}{1, 2}, `[1, 2]`},
+			{"not-exists", nil, `[]`},
+		}
+
+		route := func(name string, params []interface{}) (s string) {
+			defer func() {
+				if recover() != nil {
+					s = "error"
+				}
+			}()
+			return app.Route(name, params...)
+		}
+
+		var input []json.RawMessage
+		for _, c := range cases {
+			input = append(input, json.RawMessage(`{"name": "`+c.Name+`", "params": `+c.JS+`}`))
+		}
+		in, _ := json.Marshal(input)
+
+		dir := t.TempDir()
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "routes.mjs"), app.RoutesJS(), 0644))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "main.mjs"), []byte(`
+import { route } from "./routes.mjs";
+
+const cases = JSON.parse(process.argv[2]);
+console.log(JSON.stringify(cases.map((c) => {
+	try {
+		return route(c.name, ...c.params);
+	} catch (e) {
+		return "error";
+	}
+})));
+`), 0644))
+
+		out, err := exec.Command(node, filepath.Join(dir, "main.mjs"), string(in)).Output()
+		require.NoError(t, err)
+
+		var rs []string
+		require.NoError(t, json.Unmarshal(out, &rs))
+		require.Len(t, rs, len(cases))
+		for i, c := range cases {
+			assert.Equal(t, route(c.Name, c.Go), rs[i], "%s %s", c.Name, c.JS)
+		}
+	})
+
+	t.Run("Handler", func(t *testing.T) {
+		cases := []struct {
+			Path        string
+			ContentType string
+			Body        []byte
+		}{
+			{"/routes", "application/json; charset=utf-8", newApp().RoutesJSON()},
+			{"/routes.json", "application/json; charset=utf-8", newApp().RoutesJSON()},
+			{"/routes.js", "text/javascript; charset=utf-8", newApp().RoutesJS()},
+			{"/routes.ts", "application/typescript; charset=utf-8", newApp().RoutesTS()},
+		}
+
+		h := newApp().RoutesHandler()
+		for _, c := range cases {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, c.Path, nil))
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, c.ContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, c.Body, w.Body.Bytes())
+			assert.NotEmpty(t, w.Header().Get("ETag"))
+
+			r := httptest.NewRequest(http.MethodGet, c.Path, nil)
+			r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+			w = httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			assert.Equal(t, http.StatusNotModified, w.Code)
+			assert.Empty(t, w.Body.Bytes())
+		}
+	})
+}
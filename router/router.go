@@ -0,0 +1,248 @@
+// Package router is the optional router for hime that dispatches requests
+// to handlers using route names registered in app.Routes
+package router
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/moonrhythm/hime"
+)
+
+// Router dispatches requests to handlers by app's named routes
+type Router struct {
+	app      *hime.App
+	routes   []*route
+	mounts   []*mount
+	notFound http.Handler
+}
+
+type route struct {
+	name     string
+	segments []string
+	handlers map[string]http.Handler
+}
+
+type mount struct {
+	prefix  string
+	handler http.Handler
+}
+
+// New creates new router for given app,
+// route names must be registered in app before register handlers
+func New(app *hime.App) *Router {
+	return &Router{app: app}
+}
+
+func splitPath(p string) []string {
+	return strings.Split(strings.Trim(p, "/"), "/")
+}
+
+// splitEscapedPath splits escaped path then unescapes each segment
+func splitEscapedPath(p string) []string {
+	segments := splitPath(p)
+	for i, s := range segments {
+		if x, err := url.PathUnescape(s); err == nil {
+			segments[i] = x
+		}
+	}
+	return segments
+}
+
+func isParam(segment string) bool {
+	return len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}'
+}
+
+func (r *Router) route(name string) *route {
+	for _, rt := range r.routes {
+		if rt.name == name {
+			return rt
+		}
+	}
+
+	segments := splitPath(r.app.RoutePattern(name))
+	for _, s := range segments {
+		// router matches only param that is whole segment
+		if strings.ContainsAny(s, "{}") && !isParam(s) {
+			panic("router: route '" + name + "' has param inside path segment '" + s + "'")
+		}
+	}
+
+	rt := &route{
+		name:     name,
+		segments: segments,
+		handlers: make(map[string]http.Handler),
+	}
+	r.routes = append(r.routes, rt)
+	return rt
+}
+
+// Handle registers handler for given method and route name,
+// panics if route has param that is not whole path segment (e.g. /files/{name}.json)
+func (r *Router) Handle(method, name string, h http.Handler) *Router {
+	rt := r.route(name)
+	if _, ok := rt.handlers[method]; ok {
+		panic("router: handler for " + method + " '" + name + "' already exists")
+	}
+	rt.handlers[method] = h
+	return r
+}
+
+// Get registers GET handler for route name
+func (r *Router) Get(name string, h http.Handler) *Router {
+	return r.Handle(http.MethodGet, name, h)
+}
+
+// Head registers HEAD handler for route name
+func (r *Router) Head(name string, h http.Handler) *Router {
+	return r.Handle(http.MethodHead, name, h)
+}
+
+// Post registers POST handler for route name
+func (r *Router) Post(name string, h http.Handler) *Router {
+	return r.Handle(http.MethodPost, name, h)
+}
+
+// Put registers PUT handler for route name
+func (r *Router) Put(name string, h http.Handler) *Router {
+	return r.Handle(http.MethodPut, name, h)
+}
+
+// Patch registers PATCH handler for route name
+func (r *Router) Patch(name string, h http.Handler) *Router {
+	return r.Handle(http.MethodPatch, name, h)
+}
+
+// Delete registers DELETE handler for route name
+func (r *Router) Delete(name string, h http.Handler) *Router {
+	return r.Handle(http.MethodDelete, name, h)
+}
+
+// Options registers OPTIONS handler for route name,
+// router responds OPTIONS automatically if not registered
+func (r *Router) Options(name string, h http.Handler) *Router {
+	return r.Handle(http.MethodOptions, name, h)
+}
+
+// Mount mounts handler at path prefix,
+// prefix is stripped from request's path before call handler
+func (r *Router) Mount(prefix string, h http.Handler) *Router {
+	prefix = "/" + strings.Trim(prefix, "/")
+	if prefix != "/" {
+		h = http.StripPrefix(prefix, h)
+	}
+	r.mounts = append(r.mounts, &mount{
+		prefix:  prefix,
+		handler: h,
+	})
+	sort.SliceStable(r.mounts, func(i, j int) bool {
+		return len(r.mounts[i].prefix) > len(r.mounts[j].prefix)
+	})
+	return r
+}
+
+// NotFound sets not found handler
+//
+// default is http.NotFound
+func (r *Router) NotFound(h http.Handler) *Router {
+	r.notFound = h
+	return r
+}
+
+// match matches path segments with route,
+// returns matched params and number of static segments
+func (rt *route) match(segments []string) (map[string]string, int, bool) {
+	if len(segments) != len(rt.segments) {
+		return nil, 0, false
+	}
+
+	var params map[string]string
+	static := 0
+	for i, s := range rt.segments {
+		if isParam(s) {
+			if segments[i] == "" {
+				return nil, 0, false
+			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[s[1:len(s)-1]] = segments[i]
+			continue
+		}
+		if s != segments[i] {
+			return nil, 0, false
+		}
+		static++
+	}
+	return params, static, true
+}
+
+func (rt *route) allow() string {
+	methods := make([]string, 0, len(rt.handlers)+2)
+	for m := range rt.handlers {
+		methods = append(methods, m)
+	}
+	if _, ok := rt.handlers[http.MethodGet]; ok {
+		if _, ok := rt.handlers[http.MethodHead]; !ok {
+			methods = append(methods, http.MethodHead)
+		}
+	}
+	if _, ok := rt.handlers[http.MethodOptions]; !ok {
+		methods = append(methods, http.MethodOptions)
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	segments := splitEscapedPath(req.URL.EscapedPath())
+
+	var (
+		matched     *route
+		params      map[string]string
+		matchStatic = -1
+	)
+	for _, rt := range r.routes {
+		ps, static, ok := rt.match(segments)
+		if ok && static > matchStatic {
+			matched, params, matchStatic = rt, ps, static
+		}
+	}
+
+	if matched == nil {
+		for _, m := range r.mounts {
+			if req.URL.Path == m.prefix || strings.HasPrefix(req.URL.Path, m.prefix+"/") || m.prefix == "/" {
+				m.handler.ServeHTTP(w, req)
+				return
+			}
+		}
+
+		if r.notFound != nil {
+			r.notFound.ServeHTTP(w, req)
+			return
+		}
+		http.NotFound(w, req)
+		return
+	}
+
+	h := matched.handlers[req.Method]
+	if h == nil && req.Method == http.MethodHead {
+		h = matched.handlers[http.MethodGet]
+	}
+	if h == nil {
+		w.Header().Set("Allow", matched.allow())
+		if req.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if params != nil {
+		req = req.WithContext(hime.WithPathParams(req.Context(), params))
+	}
+	h.ServeHTTP(w, req)
+}
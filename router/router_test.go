@@ -0,0 +1,138 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/moonrhythm/hime"
+)
+
+func newApp() *hime.App {
+	return hime.New().Routes(hime.Routes{
+		"index":     "/",
+		"user":      "/users/{id}",
+		"userNew":   "/users/new",
+		"userPost":  "/users/{id}/posts/{slug}",
+		"notExists": "/not-registered",
+	})
+}
+
+func serve(app *hime.App, h http.Handler, method, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(method, target, nil)
+	app.Handler(h).ServeHTTP(w, r)
+	return w
+}
+
+func text(s string) hime.Handler {
+	return func(ctx *hime.Context) error {
+		return ctx.String(s)
+	}
+}
+
+func TestRouter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("dispatch", func(t *testing.T) {
+		app := newApp()
+		r := New(app).
+			Get("index", text("index")).
+			Get("user", hime.Handler(func(ctx *hime.Context) error {
+				return ctx.String("user " + ctx.PathParam("id"))
+			})).
+			Post("user", text("update user")).
+			Get("userNew", text("new user")).
+			Get("userPost", hime.Handler(func(ctx *hime.Context) error {
+				return ctx.String(ctx.PathParam("id") + " " + hime.PathParam(ctx, "slug"))
+			}))
+
+		cases := []struct {
+			Method string
+			Target string
+			Body   string
+		}{
+			{http.MethodGet, "/", "index"},
+			{http.MethodGet, "/users/1", "user 1"},
+			{http.MethodPost, "/users/1", "update user"},
+			{http.MethodGet, "/users/new", "new user"},
+			{http.MethodGet, "/users/1/posts/hello%20world", "1 hello world"},
+			{http.MethodGet, "/users/1/posts/a%2Fb", "1 a/b"},
+		}
+
+		for _, c := range cases {
+			w := serve(app, r, c.Method, c.Target)
+			assert.Equal(t, http.StatusOK, w.Code, c.Target)
+			assert.Equal(t, c.Body, w.Body.String(), c.Target)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		app := newApp()
+		r := New(app).Get("user", text("user"))
+
+		assert.Equal(t, http.StatusNotFound, serve(app, r, http.MethodGet, "/users").Code)
+		assert.Equal(t, http.StatusNotFound, serve(app, r, http.MethodGet, "/users/1/a").Code)
+		assert.Equal(t, http.StatusNotFound, serve(app, r, http.MethodGet, "/not-registered").Code)
+
+		r.NotFound(text("custom not found"))
+		assert.Equal(t, "custom not found", serve(app, r, http.MethodGet, "/users").Body.String())
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		app := newApp()
+		r := New(app).Get("user", text("user")).Delete("user", text("delete"))
+
+		w := serve(app, r, http.MethodPost, "/users/1")
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "DELETE, GET, HEAD, OPTIONS", w.Header().Get("Allow"))
+	})
+
+	t.Run("automatic OPTIONS and HEAD", func(t *testing.T) {
+		app := newApp()
+		r := New(app).Get("user", text("user")).Put("user", text("put")).Patch("user", text("patch"))
+
+		w := serve(app, r, http.MethodOptions, "/users/1")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "GET, HEAD, OPTIONS, PATCH, PUT", w.Header().Get("Allow"))
+
+		w = serve(app, r, http.MethodHead, "/users/1")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		r.Options("user", text("custom options")).Head("index", text("head"))
+		assert.Equal(t, "custom options", serve(app, r, http.MethodOptions, "/users/1").Body.String())
+		assert.Equal(t, "HEAD, OPTIONS", serve(app, r, http.MethodGet, "/").Header().Get("Allow"))
+	})
+
+	t.Run("Mount", func(t *testing.T) {
+		app := newApp()
+		api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("api " + r.URL.Path))
+		})
+		r := New(app).
+			Get("user", text("user")).
+			Mount("/api/", api).
+			Mount("/api/v2", text("v2"))
+
+		assert.Equal(t, "api /users", serve(app, r, http.MethodGet, "/api/users").Body.String())
+		assert.Equal(t, "v2", serve(app, r, http.MethodGet, "/api/v2/users").Body.String())
+		assert.Equal(t, http.StatusNotFound, serve(app, r, http.MethodGet, "/apix").Code)
+
+		r.Mount("/", text("root"))
+		assert.Equal(t, "root", serve(app, r, http.MethodGet, "/apix").Body.String())
+		assert.Equal(t, "user", serve(app, r, http.MethodGet, "/users/1").Body.String())
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		r := New(newApp()).Get("user", text("user"))
+		assert.Panics(t, func() { r.Get("user", text("user")) })
+		assert.Panics(t, func() { r.Get("invalid", text("invalid")) })
+
+		app := hime.New().Routes(hime.Routes{"file": "/files/{name}.json"})
+		assert.PanicsWithValue(t, "router: route 'file' has param inside path segment '{name}.json'", func() {
+			New(app).Get("file", text("file"))
+		})
+	})
+}
@@ -1,10 +1,14 @@
 package hime
 
 import (
+	"io/ioutil"
+	"net"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHTTPSRedirect(t *testing.T) {
@@ -18,3 +22,26 @@ func TestHTTPSRedirect(t *testing.T) {
 	srv.Handler.ServeHTTP(w, r)
 	assert.Equal(t, w.Header().Get("Location"), "https://localhost/test")
 }
+
+func freeAddr(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+func get(t *testing.T, addr string) string {
+	resp, err := http.Get("http://" + addr)
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	defer resp.Body.Close()
+	b, _ := ioutil.ReadAll(resp.Body)
+	return string(b)
+}
+
+func stringHandler(s string) http.Handler {
+	return Handler(func(ctx *Context) error {
+		return ctx.String(s)
+	})
+}
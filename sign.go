@@ -0,0 +1,110 @@
+package hime
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// query param names for signed url
+const (
+	signatureParam        = "signature"
+	signatureExpiresParam = "expires"
+)
+
+// SignatureKeys sets keys for signed url,
+// the first key is used to sign, all keys are used to verify,
+// so old keys can be kept after rotate
+func (app *App) SignatureKeys(keys ...[]byte) *App {
+	app.signatureKeys = keys
+	return app
+}
+
+// SignedRoute gets route path from given name then appends expiry and signature,
+// ttl <= 0 signs without expiry
+func (app *App) SignedRoute(name string, ttl time.Duration, params ...interface{}) string {
+	if len(app.signatureKeys) == 0 {
+		panicf("signature keys not set")
+	}
+
+	if ttl > 0 {
+		params = append(params[:len(params):len(params)], &Param{
+			Name:  signatureExpiresParam,
+			Value: time.Now().Add(ttl).Unix(),
+		})
+	}
+
+	u, err := url.Parse(app.Route(name, params...))
+	if err != nil {
+		panicf("can not parse route; %v", err)
+	}
+	q := u.Query()
+	q.Del(signatureParam)
+	q.Set(signatureParam, sign(app.signatureKeys[0], u.EscapedPath(), q))
+	return u.EscapedPath() + "?" + q.Encode()
+}
+
+// VerifySignature verifies request url that generated from app.SignedRoute,
+// returns ErrSignatureInvalid if url was modified or signed with unknown key,
+// or *ErrSignatureExpired if url was expired
+func (ctx *Context) VerifySignature() error {
+	if len(ctx.app.signatureKeys) == 0 {
+		panicf("signature keys not set")
+	}
+
+	q, err := url.ParseQuery(ctx.URL.RawQuery)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+	sig := q.Get(signatureParam)
+	if sig == "" {
+		return ErrSignatureInvalid
+	}
+	q.Del(signatureParam)
+
+	p := ctx.app.basePath + ctx.URL.EscapedPath()
+	valid := false
+	for _, key := range ctx.app.signatureKeys {
+		if hmac.Equal([]byte(sig), []byte(sign(key, p, q))) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return ErrSignatureInvalid
+	}
+
+	if s := q.Get(signatureExpiresParam); s != "" {
+		exp, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return ErrSignatureInvalid
+		}
+		if t := time.Unix(exp, 0); !time.Now().Before(t) {
+			return &ErrSignatureExpired{Expires: t}
+		}
+	}
+
+	return nil
+}
+
+// sign signs path and sorted query with hmac-sha256
+func sign(key []byte, path string, q url.Values) string {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(path))
+	h.Write([]byte("?"))
+	h.Write([]byte(q.Encode()))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+func cloneSignatureKeys(xs [][]byte) [][]byte {
+	if xs == nil {
+		return nil
+	}
+
+	rs := make([][]byte, len(xs))
+	copy(rs, xs)
+	return rs
+}
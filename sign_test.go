@@ -0,0 +1,107 @@
+package hime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignedRoute(t *testing.T) {
+	t.Parallel()
+
+	newApp := func() *App {
+		return New().
+			Routes(Routes{"download": "/files/{id}"}).
+			SignatureKeys([]byte("secret"))
+	}
+
+	verify := func(app *App, target string) error {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		return NewAppContext(app, w, r).VerifySignature()
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		app := newApp()
+		p := app.SignedRoute("download", time.Hour, 1, &Param{"name", "a b"})
+		assert.True(t, strings.HasPrefix(p, "/files/1?"))
+		assert.Contains(t, p, "expires=")
+		assert.Contains(t, p, "signature=")
+		assert.NoError(t, verify(app, p))
+	})
+
+	t.Run("Without expiry", func(t *testing.T) {
+		app := newApp()
+		p := app.SignedRoute("download", 0, 1)
+		assert.NotContains(t, p, "expires=")
+		assert.NoError(t, verify(app, p))
+	})
+
+	t.Run("Query order", func(t *testing.T) {
+		app := newApp()
+		p := app.SignedRoute("download", 0, 1, &Param{"b", "2"}, &Param{"a", "1"})
+		u, _ := url.Parse(p)
+		q := u.Query()
+		assert.NoError(t, verify(app, "/files/1?signature="+q.Get("signature")+"&b=2&a=1"))
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		app := newApp()
+		p := app.SignedRoute("download", time.Hour, 1)
+		assert.Equal(t, ErrSignatureInvalid, verify(app, strings.Replace(p, "/files/1", "/files/2", 1)))
+		assert.Equal(t, ErrSignatureInvalid, verify(app, p+"&admin=1"))
+		assert.Equal(t, ErrSignatureInvalid, verify(app, "/files/1"))
+	})
+
+	t.Run("Extend expiry", func(t *testing.T) {
+		app := newApp()
+		p := app.SignedRoute("download", time.Hour, 1)
+		u, _ := url.Parse(p)
+		q := u.Query()
+		q.Set("expires", strconv.FormatInt(time.Now().Add(48*time.Hour).Unix(), 10))
+		assert.Equal(t, ErrSignatureInvalid, verify(app, u.Path+"?"+q.Encode()))
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		app := newApp()
+		exp := time.Now().Add(-time.Minute).Unix()
+		q := url.Values{"expires": {strconv.FormatInt(exp, 10)}}
+		q.Set("signature", sign([]byte("secret"), "/files/1", q))
+
+		err := verify(app, "/files/1?"+q.Encode())
+		if assert.IsType(t, &ErrSignatureExpired{}, err) {
+			assert.Equal(t, exp, err.(*ErrSignatureExpired).Expires.Unix())
+		}
+	})
+
+	t.Run("Key rotation", func(t *testing.T) {
+		old := newApp()
+		p := old.SignedRoute("download", time.Hour, 1)
+
+		app := newApp().SignatureKeys([]byte("new"), []byte("secret"))
+		assert.NoError(t, verify(app, p))
+		assert.NoError(t, verify(app, app.SignedRoute("download", time.Hour, 1)))
+		assert.Equal(t, ErrSignatureInvalid, verify(old, app.SignedRoute("download", time.Hour, 1)))
+
+		app.SignatureKeys([]byte("new"))
+		assert.Equal(t, ErrSignatureInvalid, verify(app, p))
+	})
+
+	t.Run("Keys not set", func(t *testing.T) {
+		app := New().Routes(Routes{"download": "/files/{id}"})
+		assert.Panics(t, func() { app.SignedRoute("download", time.Hour, 1) })
+		assert.Panics(t, func() { verify(app, "/files/1") })
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		app := New().ParseConfig([]byte(`signatureKeys: [new, old]`))
+		assert.Equal(t, [][]byte{[]byte("new"), []byte("old")}, app.signatureKeys)
+		assert.Equal(t, app.signatureKeys, app.Clone().signatureKeys)
+	})
+}
@@ -0,0 +1,302 @@
+package hime
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sitemapMaxURLs is the maximum urls in a sitemap file
+const sitemapMaxURLs = 50000
+
+var sitemapChangeFreqs = map[string]bool{
+	"always":  true,
+	"hourly":  true,
+	"daily":   true,
+	"weekly":  true,
+	"monthly": true,
+	"yearly":  true,
+	"never":   true,
+}
+
+// SitemapRoute is the sitemap metadata for a route
+type SitemapRoute struct {
+	// ChangeFreq is always, hourly, daily, weekly, monthly, yearly or never
+	ChangeFreq string `yaml:"changefreq" json:"changefreq"`
+
+	// Priority is between 0.0 and 1.0, 0 omits priority
+	Priority float64 `yaml:"priority" json:"priority"`
+
+	// LastMod returns last modified time for route's url with params,
+	// zero time omits lastmod
+	LastMod func(ctx context.Context, params ...interface{}) time.Time `yaml:"-" json:"-"`
+
+	// Enumerate returns params for every url of route,
+	// required for route that has named params
+	Enumerate func(ctx context.Context) ([][]interface{}, error) `yaml:"-" json:"-"`
+}
+
+// Sitemap returns app's sitemap,
+// sitemap serves sitemap xml for registered routes
+//
+//	app.Sitemap().
+//		Route("index", hime.SitemapRoute{ChangeFreq: "daily", Priority: 1}).
+//		Enumerate("post", listPostParams)
+//	mux.Handle("/sitemap.xml", app.Sitemap())
+func (app *App) Sitemap() *Sitemap {
+	if app.sitemap == nil {
+		app.sitemap = &Sitemap{
+			app:    app,
+			routes: make(map[string]*SitemapRoute),
+		}
+	}
+
+	return app.sitemap
+}
+
+// Sitemap is the sitemap xml handler
+type Sitemap struct {
+	app    *App
+	routes map[string]*SitemapRoute
+}
+
+// Route adds route to sitemap,
+// nil funcs keep funcs that already set for the route
+func (sm *Sitemap) Route(name string, r SitemapRoute) *Sitemap {
+	if r.ChangeFreq != "" && !sitemapChangeFreqs[r.ChangeFreq] {
+		panicf("sitemap route '%s' invalid changefreq '%s'", name, r.ChangeFreq)
+	}
+	if r.Priority < 0 || r.Priority > 1 {
+		panicf("sitemap route '%s' invalid priority %v", name, r.Priority)
+	}
+
+	x := sm.route(name)
+	x.ChangeFreq = r.ChangeFreq
+	x.Priority = r.Priority
+	if r.LastMod != nil {
+		x.LastMod = r.LastMod
+	}
+	if r.Enumerate != nil {
+		x.Enumerate = r.Enumerate
+	}
+	return sm
+}
+
+func (sm *Sitemap) route(name string) *SitemapRoute {
+	r := sm.routes[name]
+	if r == nil {
+		r = &SitemapRoute{}
+		sm.routes[name] = r
+	}
+	return r
+}
+
+// LastMod sets last modified time provider for route
+func (sm *Sitemap) LastMod(name string, fn func(ctx context.Context, params ...interface{}) time.Time) *Sitemap {
+	sm.route(name).LastMod = fn
+	return sm
+}
+
+// Enumerate sets params provider for route
+func (sm *Sitemap) Enumerate(name string, fn func(ctx context.Context) ([][]interface{}, error)) *Sitemap {
+	sm.route(name).Enumerate = fn
+	return sm
+}
+
+// Config loads sitemap routes metadata from config,
+// keeps funcs that already set
+func (sm *Sitemap) Config(routes map[string]SitemapRoute) *Sitemap {
+	for name, r := range routes {
+		sm.Route(name, r)
+	}
+	return sm
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name         `xml:"sitemapindex"`
+	Xmlns    string           `xml:"xmlns,attr"`
+	Sitemaps []sitemapURLItem `xml:"sitemap"`
+}
+
+type sitemapURLItem struct {
+	Loc string `xml:"loc"`
+}
+
+const sitemapXmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// sitemapEntry is an url of route in sitemap
+type sitemapEntry struct {
+	name   string
+	route  *SitemapRoute
+	params []interface{}
+}
+
+// entries enumerates params of every route,
+// urls are built only for entries that the response needs
+func (sm *Sitemap) entries(ctx *Context) ([]sitemapEntry, error) {
+	names := make([]string, 0, len(sm.routes))
+	for name := range sm.routes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var xs []sitemapEntry
+	for _, name := range names {
+		r := sm.routes[name]
+		path, ok := sm.app.routes[name]
+		if !ok {
+			return nil, newErrRouteNotFound(name)
+		}
+
+		paramSets := [][]interface{}{nil}
+		if r.Enumerate != nil {
+			var err error
+			paramSets, err = r.Enumerate(ctx)
+			if err != nil {
+				return nil, err
+			}
+		} else if len(routeParamNames(path)) > 0 {
+			return nil, fmt.Errorf("hime: sitemap route '%s' has params but no enumerate func", name)
+		}
+
+		for _, params := range paramSets {
+			xs = append(xs, sitemapEntry{name, r, params})
+		}
+	}
+	return xs, nil
+}
+
+func (sm *Sitemap) urls(ctx *Context, entries []sitemapEntry) (urls []sitemapURL, err error) {
+	// app.Route panics on invalid route or params
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(error)
+			if !ok {
+				e = fmt.Errorf("%v", r)
+			}
+			urls, err = nil, e
+		}
+	}()
+
+	baseURL := ctx.BaseURL()
+	urls = make([]sitemapURL, 0, len(entries))
+	for _, x := range entries {
+		u := sitemapURL{
+			Loc:        baseURL + sm.app.Route(x.name, x.params...),
+			ChangeFreq: x.route.ChangeFreq,
+		}
+		if x.route.Priority > 0 {
+			u.Priority = formatSitemapPriority(x.route.Priority)
+		}
+		if x.route.LastMod != nil {
+			if t := x.route.LastMod(ctx, x.params...); !t.IsZero() {
+				u.LastMod = t.Format(time.RFC3339)
+			}
+		}
+		urls = append(urls, u)
+	}
+	return urls, nil
+}
+
+// formatSitemapPriority formats priority without losing precision,
+// but always has at least one decimal (1 => 1.0)
+func formatSitemapPriority(p float64) string {
+	s := strconv.FormatFloat(p, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s
+}
+
+// ServeHTTP serves sitemap xml,
+// serves sitemap index when urls more than 50,000
+// which each sitemap can retrieve from ?page=n
+func (sm *Sitemap) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := NewAppContext(sm.app, w, r)
+
+	entries, err := sm.entries(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var v interface{}
+	page := r.URL.Query().Get("page")
+	switch {
+	case page != "":
+		n, _ := strconv.Atoi(page)
+		start := (n - 1) * sitemapMaxURLs
+		if n < 1 || start >= len(entries) {
+			http.NotFound(w, r)
+			return
+		}
+		end := start + sitemapMaxURLs
+		if end > len(entries) {
+			end = len(entries)
+		}
+		entries = entries[start:end]
+	case len(entries) > sitemapMaxURLs:
+		loc := ctx.BaseURL() + sm.app.basePath + r.URL.Path + "?page="
+		index := &sitemapIndex{Xmlns: sitemapXmlns}
+		for i := 0; i*sitemapMaxURLs < len(entries); i++ {
+			index.Sitemaps = append(index.Sitemaps, sitemapURLItem{Loc: loc + strconv.Itoa(i+1)})
+		}
+		v = index
+	}
+
+	if v == nil {
+		urls, err := sm.urls(ctx, entries)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		v = &sitemapURLSet{Xmlns: sitemapXmlns, URLs: urls}
+	}
+
+	buf := getBytes()
+	defer putBytes(buf)
+
+	buf.WriteString(xml.Header)
+	err = xml.NewEncoder(buf).Encode(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+func cloneSitemap(app *App, sm *Sitemap) *Sitemap {
+	if sm == nil {
+		return nil
+	}
+
+	x := &Sitemap{
+		app:    app,
+		routes: make(map[string]*SitemapRoute, len(sm.routes)),
+	}
+	for k, v := range sm.routes {
+		r := *v
+		x.routes[k] = &r
+	}
+	return x
+}
@@ -0,0 +1,164 @@
+package hime
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSitemap(t *testing.T) {
+	t.Parallel()
+
+	get := func(h http.Handler, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	t.Run("Static and dynamic routes", func(t *testing.T) {
+		app := New().
+			BaseURL("https://example.com").
+			Routes(Routes{
+				"index":   "/",
+				"about":   "/about",
+				"post":    "/posts/{slug}",
+				"private": "/private",
+			})
+		app.Sitemap().
+			Route("index", SitemapRoute{ChangeFreq: "daily", Priority: 1}).
+			Route("about", SitemapRoute{}).
+			LastMod("about", func(ctx context.Context, params ...interface{}) time.Time {
+				return time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+			}).
+			Route("about", SitemapRoute{Priority: 0.85}).
+			Route("post", SitemapRoute{
+				ChangeFreq: "weekly",
+				Priority:   0.5,
+				Enumerate: func(ctx context.Context) ([][]interface{}, error) {
+					return [][]interface{}{{"a"}, {"b c"}}, nil
+				},
+			})
+
+		w := get(app.Sitemap(), "/sitemap.xml")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`+
+			`<url><loc>https://example.com/about</loc><lastmod>2020-01-02T03:04:05Z</lastmod><priority>0.85</priority></url>`+
+			`<url><loc>https://example.com/</loc><changefreq>daily</changefreq><priority>1.0</priority></url>`+
+			`<url><loc>https://example.com/posts/a</loc><changefreq>weekly</changefreq><priority>0.5</priority></url>`+
+			`<url><loc>https://example.com/posts/b%20c</loc><changefreq>weekly</changefreq><priority>0.5</priority></url>`+
+			`</urlset>`, w.Body.String())
+	})
+
+	t.Run("Request base url", func(t *testing.T) {
+		app := New().BasePath("/app").Routes(Routes{"index": "/"})
+		app.Sitemap().Route("index", SitemapRoute{})
+
+		w := get(app.Sitemap(), "http://localhost/sitemap.xml")
+		assert.Contains(t, w.Body.String(), "<loc>http://localhost/app/</loc>")
+	})
+
+	t.Run("Index", func(t *testing.T) {
+		var lastMods int
+		app := New().BaseURL("https://example.com").Routes(Routes{"post": "/posts/{id}"})
+		app.Sitemap().Enumerate("post", func(ctx context.Context) ([][]interface{}, error) {
+			xs := make([][]interface{}, sitemapMaxURLs+1)
+			for i := range xs {
+				xs[i] = []interface{}{i}
+			}
+			return xs, nil
+		}).LastMod("post", func(ctx context.Context, params ...interface{}) time.Time {
+			lastMods++
+			return time.Time{}
+		})
+
+		w := get(app.Sitemap(), "/sitemap.xml")
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`+
+			`<sitemap><loc>https://example.com/sitemap.xml?page=1</loc></sitemap>`+
+			`<sitemap><loc>https://example.com/sitemap.xml?page=2</loc></sitemap>`+
+			`</sitemapindex>`, w.Body.String())
+		assert.Zero(t, lastMods, "index must not build urls")
+
+		w = get(app.Sitemap(), "/sitemap.xml?page=1")
+		assert.Equal(t, sitemapMaxURLs, strings.Count(w.Body.String(), "<url>"))
+
+		w = get(app.Sitemap(), "/sitemap.xml?page=2")
+		assert.Equal(t, 1, strings.Count(w.Body.String(), "<url>"))
+		assert.Contains(t, w.Body.String(), "<loc>https://example.com/posts/50000</loc>")
+		assert.Equal(t, sitemapMaxURLs+1, lastMods, "page must build only its urls")
+
+		assert.Equal(t, http.StatusNotFound, get(app.Sitemap(), "/sitemap.xml?page=3").Code)
+		assert.Equal(t, http.StatusNotFound, get(app.Sitemap(), "/sitemap.xml?page=0").Code)
+		assert.Equal(t, http.StatusNotFound, get(app.Sitemap(), "/sitemap.xml?page=x").Code)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		app := New().Routes(Routes{"post": "/posts/{id}"})
+		app.Sitemap().Route("post", SitemapRoute{})
+		assert.Equal(t, http.StatusInternalServerError, get(app.Sitemap(), "/sitemap.xml").Code)
+
+		app.Sitemap().Enumerate("post", func(ctx context.Context) ([][]interface{}, error) {
+			return nil, errors.New("db error")
+		})
+		assert.Equal(t, http.StatusInternalServerError, get(app.Sitemap(), "/sitemap.xml").Code)
+
+		app.Sitemap().Enumerate("post", func(ctx context.Context) ([][]interface{}, error) {
+			return [][]interface{}{{1, 2}}, nil
+		})
+		assert.Equal(t, http.StatusInternalServerError, get(app.Sitemap(), "/sitemap.xml").Code)
+
+		app = New()
+		app.Sitemap().Route("notfound", SitemapRoute{})
+		assert.Equal(t, http.StatusInternalServerError, get(app.Sitemap(), "/sitemap.xml").Code)
+	})
+
+	t.Run("Route keeps funcs", func(t *testing.T) {
+		sm := New().Sitemap()
+		sm.LastMod("a", func(ctx context.Context, params ...interface{}) time.Time { return time.Time{} })
+		sm.Route("a", SitemapRoute{ChangeFreq: "daily"})
+		sm.Route("a", SitemapRoute{Priority: 0.5})
+
+		assert.NotNil(t, sm.routes["a"].LastMod)
+		assert.Equal(t, "", sm.routes["a"].ChangeFreq)
+		assert.Equal(t, 0.5, sm.routes["a"].Priority)
+	})
+
+	t.Run("Invalid metadata", func(t *testing.T) {
+		assert.Panics(t, func() { New().Sitemap().Route("a", SitemapRoute{ChangeFreq: "sometimes"}) })
+		assert.Panics(t, func() { New().Sitemap().Route("a", SitemapRoute{Priority: 1.5}) })
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		app := New()
+		app.Sitemap().Enumerate("post", func(ctx context.Context) ([][]interface{}, error) { return nil, nil })
+		app.ParseConfig([]byte(`
+routes:
+  index: /
+  post: /posts/{id}
+sitemap:
+  index: {changefreq: daily, priority: 1.0}
+  post: {changefreq: weekly}`))
+
+		sm := app.Sitemap()
+		if assert.Contains(t, sm.routes, "index") {
+			assert.Equal(t, "daily", sm.routes["index"].ChangeFreq)
+			assert.Equal(t, 1.0, sm.routes["index"].Priority)
+		}
+		if assert.Contains(t, sm.routes, "post") {
+			assert.Equal(t, "weekly", sm.routes["post"].ChangeFreq)
+			assert.NotNil(t, sm.routes["post"].Enumerate)
+		}
+
+		x := app.Clone()
+		assert.True(t, x.sitemap.app == x)
+		assert.Equal(t, "daily", x.sitemap.routes["index"].ChangeFreq)
+	})
+}
@@ -0,0 +1,234 @@
+package hime
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// StdFuncs returns hime's standard template func library
+//
+// dict, list, default, coalesce, json, safeHTML, safeURL, safeJS,
+// date, number, currency, pluralize, truncate, query
+func StdFuncs() template.FuncMap {
+	return template.FuncMap{
+		"dict":      tfDict,
+		"list":      tfList,
+		"default":   tfDefault,
+		"coalesce":  tfCoalesce,
+		"json":      tfJSON,
+		"safeHTML":  tfSafeHTML,
+		"safeURL":   tfSafeURL,
+		"safeJS":    tfSafeJS,
+		"date":      tfDate,
+		"number":    tfNumber,
+		"currency":  tfCurrency,
+		"pluralize": tfPluralize,
+		"truncate":  tfTruncate,
+		"query":     buildPath,
+	}
+}
+
+// StdFuncs adds hime's standard template func library
+func (tp *Template) StdFuncs() *Template {
+	return tp.Funcs(StdFuncs())
+}
+
+// tfDict creates map from key-value pairs
+func tfDict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("hime: dict requires key-value pairs got %d args", len(pairs))
+	}
+
+	m := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		k, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("hime: dict key must be string got %T", pairs[i])
+		}
+		m[k] = pairs[i+1]
+	}
+	return m, nil
+}
+
+func tfList(xs ...interface{}) []interface{} {
+	return xs
+}
+
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	}
+	return rv.IsZero()
+}
+
+// tfDefault returns v if v is not empty, or def
+//
+// {{default "guest" .Name}}
+func tfDefault(def interface{}, v interface{}) interface{} {
+	if isEmpty(v) {
+		return def
+	}
+	return v
+}
+
+// tfCoalesce returns first not empty value
+func tfCoalesce(xs ...interface{}) interface{} {
+	for _, x := range xs {
+		if !isEmpty(x) {
+			return x
+		}
+	}
+	return nil
+}
+
+func tfJSON(v interface{}) (template.JS, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return template.JS(b), nil
+}
+
+func tfSafeHTML(s string) template.HTML {
+	return template.HTML(s)
+}
+
+func tfSafeURL(s string) template.URL {
+	return template.URL(s)
+}
+
+func tfSafeJS(s string) template.JS {
+	return template.JS(s)
+}
+
+// tfDate formats time with layout in optional time zone
+//
+// {{date "2006-01-02 15:04" .CreatedAt "Asia/Bangkok"}}
+func tfDate(layout string, t interface{}, tz ...string) (string, error) {
+	var tt time.Time
+	switch v := t.(type) {
+	case time.Time:
+		tt = v
+	case *time.Time:
+		if v == nil {
+			return "", nil
+		}
+		tt = *v
+	default:
+		return "", fmt.Errorf("hime: date requires time got %T", t)
+	}
+
+	if len(tz) > 0 {
+		loc, err := time.LoadLocation(tz[0])
+		if err != nil {
+			return "", err
+		}
+		tt = tt.In(loc)
+	}
+
+	return tt.Format(layout), nil
+}
+
+func toFloat64(v interface{}) (float64, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.String:
+		return strconv.ParseFloat(removeComma(rv.String()), 64)
+	}
+	return 0, fmt.Errorf("hime: can not convert %T to number", v)
+}
+
+func formatNumber(f float64, decimals int) string {
+	s := strconv.FormatFloat(f, 'f', decimals, 64)
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i:]
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	b.WriteString(fracPart)
+	return b.String()
+}
+
+// tfNumber formats number with thousands separator
+//
+// {{number .Amount 2}} => 1,234.50
+func tfNumber(v interface{}, decimals int) (string, error) {
+	f, err := toFloat64(v)
+	if err != nil {
+		return "", err
+	}
+	return formatNumber(f, decimals), nil
+}
+
+// tfCurrency formats number with symbol and 2 decimals
+//
+// {{currency "$" .Amount}} => $1,234.50
+func tfCurrency(symbol string, v interface{}) (string, error) {
+	f, err := toFloat64(v)
+	if err != nil {
+		return "", err
+	}
+	if f < 0 {
+		return "-" + symbol + formatNumber(-f, 2), nil
+	}
+	return symbol + formatNumber(f, 2), nil
+}
+
+// tfPluralize returns singular if n is 1, or plural
+//
+// {{.Count}} {{pluralize .Count "item" "items"}}
+func tfPluralize(n interface{}, singular, plural string) (string, error) {
+	f, err := toFloat64(n)
+	if err != nil {
+		return "", err
+	}
+	if f == 1 {
+		return singular, nil
+	}
+	return plural, nil
+}
+
+// tfTruncate truncates s to n characters and appends "..." if truncated
+func tfTruncate(n int, s string) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	if n <= 0 {
+		return ""
+	}
+	return string([]rune(s)[:n]) + "..."
+}
@@ -0,0 +1,156 @@
+package hime
+
+import (
+	"bytes"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStdFuncs(t *testing.T) {
+	t.Parallel()
+
+	render := func(t *testing.T, text string, data interface{}) string {
+		t.Helper()
+
+		tp := New().Template().StdFuncs()
+		tp.Parse("t", text)
+
+		var b bytes.Buffer
+		if !assert.NoError(t, tp.list["t"].Execute(&b, data)) {
+			return ""
+		}
+		return b.String()
+	}
+
+	t.Run("dict", func(t *testing.T) {
+		assert.Equal(t, "1 b", render(t, `{{$d := dict "a" 1 "b" "b"}}{{$d.a}} {{$d.b}}`, nil))
+
+		_, err := tfDict("a")
+		assert.Error(t, err)
+		_, err = tfDict(1, "a")
+		assert.Error(t, err)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		assert.Equal(t, "a,b,", render(t, `{{range list "a" "b"}}{{.}},{{end}}`, nil))
+	})
+
+	t.Run("default", func(t *testing.T) {
+		assert.Equal(t, "guest", render(t, `{{default "guest" .}}`, ""))
+		assert.Equal(t, "hime", render(t, `{{default "guest" .}}`, "hime"))
+		assert.Equal(t, "guest", render(t, `{{default "guest" .}}`, nil))
+		assert.Equal(t, "0", render(t, `{{default 0 .}}`, []int{}))
+	})
+
+	t.Run("coalesce", func(t *testing.T) {
+		assert.Equal(t, "b", render(t, `{{coalesce .A .B "c"}}`, map[string]interface{}{"A": "", "B": "b"}))
+		assert.Nil(t, tfCoalesce(nil, ""))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		assert.Equal(t,
+			`<script>var x = {"a":"\u003c/script\u003e"};</script>`,
+			render(t, `<script>var x = {{json .}};</script>`, map[string]string{"a": "</script>"}),
+		)
+		assert.Equal(t,
+			`<p>{&#34;a&#34;:1}</p>`,
+			render(t, `<p>{{json .}}</p>`, map[string]int{"a": 1}),
+		)
+
+		_, err := tfJSON(func() {})
+		assert.Error(t, err)
+	})
+
+	t.Run("safe", func(t *testing.T) {
+		assert.Equal(t, "<b>a</b> &lt;b&gt;a&lt;/b&gt;", render(t, `{{safeHTML .}} {{.}}`, "<b>a</b>"))
+		assert.Equal(t, `<a href="javascript:void%280%29"></a>`, render(t, `<a href="{{safeURL .}}"></a>`, "javascript:void(0)"))
+		assert.Equal(t, `<a href="#ZgotmplZ"></a>`, render(t, `<a href="{{.}}"></a>`, "javascript:void(0)"))
+		assert.Equal(t, `<script>var a = 1 + 2;</script>`, render(t, `<script>var a = {{safeJS .}};</script>`, "1 + 2"))
+	})
+
+	t.Run("date", func(t *testing.T) {
+		tm := time.Date(2021, 1, 2, 20, 4, 5, 0, time.UTC)
+		assert.Equal(t, "2021-01-02 20:04", render(t, `{{date "2006-01-02 15:04" .}}`, tm))
+		assert.Equal(t, "2021-01-03 03:04", render(t, `{{date "2006-01-02 15:04" . "Asia/Bangkok"}}`, &tm))
+
+		_, err := tfDate("2006", tm, "Invalid/Zone")
+		assert.Error(t, err)
+		_, err = tfDate("2006", "2021")
+		assert.Error(t, err)
+		s, err := tfDate("2006", (*time.Time)(nil))
+		assert.NoError(t, err)
+		assert.Empty(t, s)
+	})
+
+	t.Run("number", func(t *testing.T) {
+		cases := []struct {
+			Input    interface{}
+			Decimals int
+			Output   string
+		}{
+			{0, 0, "0"},
+			{123, 0, "123"},
+			{1234, 0, "1,234"},
+			{-1234567, 0, "-1,234,567"},
+			{1234.5, 2, "1,234.50"},
+			{uint(1000000), 1, "1,000,000.0"},
+			{"12,345.678", 2, "12,345.68"},
+		}
+
+		for _, c := range cases {
+			s, err := tfNumber(c.Input, c.Decimals)
+			assert.NoError(t, err)
+			assert.Equal(t, c.Output, s)
+		}
+
+		_, err := tfNumber(struct{}{}, 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("currency", func(t *testing.T) {
+		assert.Equal(t, "$1,234.50", render(t, `{{currency "$" .}}`, 1234.5))
+		assert.Equal(t, "-฿1,000.00", render(t, `{{currency "฿" .}}`, -1000))
+
+		_, err := tfCurrency("$", "x")
+		assert.Error(t, err)
+	})
+
+	t.Run("pluralize", func(t *testing.T) {
+		assert.Equal(t, "1 item", render(t, `{{.}} {{pluralize . "item" "items"}}`, 1))
+		assert.Equal(t, "2 items", render(t, `{{.}} {{pluralize . "item" "items"}}`, 2))
+		assert.Equal(t, "0 items", render(t, `{{.}} {{pluralize . "item" "items"}}`, 0))
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		assert.Equal(t, "hello", tfTruncate(10, "hello"))
+		assert.Equal(t, "he...", tfTruncate(2, "hello"))
+		assert.Equal(t, "สวั...", tfTruncate(3, "สวัสดี"))
+		assert.Equal(t, "", tfTruncate(0, "hello"))
+		assert.Equal(t, "&lt;b&gt;...", render(t, `{{truncate 3 .}}`, "<b>hello</b>"))
+	})
+
+	t.Run("query", func(t *testing.T) {
+		assert.Equal(t,
+			`<a href="/search?page=2&amp;q=a&#43;b"></a>`,
+			render(t, `<a href="{{query "/search" (dict "q" "a b" "page" 2)}}"></a>`, nil),
+		)
+		assert.Equal(t, "/a/b?id=1", render(t, `{{query "/a" "b" .}}`, url.Values{"id": {"1"}}))
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		tp := New().Template()
+		tp.ParseConfig([]byte(`
+stdFuncs: true
+dir: testdata/template
+list:
+  k: [k1.tmpl]`))
+
+		tp.Parse("t", `{{default "a" ""}}`)
+		var b bytes.Buffer
+		assert.NoError(t, tp.list["t"].Execute(&b, nil))
+		assert.Equal(t, "a", b.String())
+	})
+}
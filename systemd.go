@@ -0,0 +1,185 @@
+package hime
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sdListenFDsStart is the first file descriptor passed by systemd,
+// see sd_listen_fds(3)
+var sdListenFDsStart = 3
+
+type inheritedListener struct {
+	name string
+	ln   net.Listener
+}
+
+// inherited holds listeners that passed from systemd socket activation,
+// each listener can take only once
+var inherited struct {
+	mu     sync.Mutex
+	loaded bool
+	list   []*inheritedListener
+}
+
+// loadInheritedListeners loads listeners from systemd's LISTEN_FDS
+// (LISTEN_PID must be current process) or from old process when upgrade
+func loadInheritedListeners() error {
+	if inherited.loaded {
+		return nil
+	}
+	inherited.loaded = true
+
+	if os.Getenv("HIME_UPGRADE_FDS") != "" {
+		return loadUpgradeListeners()
+	}
+
+	pid, _ := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if pid != os.Getpid() {
+		return nil
+	}
+	n, _ := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	// unset to not pass to child process
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	return loadListenerFiles(sdListenFDsStart, n, names)
+}
+
+// loadListenerFiles loads n listeners from file descriptors start from start
+func loadListenerFiles(start, n int, names []string) error {
+	for i := 0; i < n; i++ {
+		var name string
+		if i < len(names) {
+			name = names[i]
+		}
+
+		f := os.NewFile(uintptr(start+i), name)
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		inherited.list = append(inherited.list, &inheritedListener{name: name, ln: ln})
+	}
+	return nil
+}
+
+// takeInheritedListeners takes inherited listeners that match name,
+// empty name takes listeners in order without check name,
+// max <= 0 takes all matched listeners
+func takeInheritedListeners(name string, max int) ([]net.Listener, error) {
+	inherited.mu.Lock()
+	defer inherited.mu.Unlock()
+
+	err := loadInheritedListeners()
+	if err != nil {
+		return nil, err
+	}
+
+	var lns []net.Listener
+	for i, x := range inherited.list {
+		if max > 0 && len(lns) >= max {
+			break
+		}
+		if x == nil || (name != "" && x.name != name) {
+			continue
+		}
+		lns = append(lns, x.ln)
+		inherited.list[i] = nil
+	}
+	return lns, nil
+}
+
+// ListenerName sets name of listener that app takes from systemd socket activation
+// (FileDescriptorName in socket unit), app without name takes all passed listeners
+func (app *App) ListenerName(name string) *App {
+	app.listenerName = name
+	return app
+}
+
+// sdNotify sends state to systemd's notify socket,
+// does nothing if NOTIFY_SOCKET not set or in prefork child,
+// see sd_notify(3)
+func sdNotify(state string) error {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" || isPreforkChild() {
+		return nil
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// sdWatchdogInterval returns interval to send watchdog keep-alive,
+// returns 0 if watchdog is not enabled for current process
+func sdWatchdogInterval() time.Duration {
+	usec, _ := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if usec <= 0 {
+		return 0
+	}
+	if s := os.Getenv("WATCHDOG_PID"); s != "" {
+		pid, _ := strconv.Atoi(s)
+		if pid != os.Getpid() {
+			return 0
+		}
+	}
+	return time.Duration(usec) * time.Microsecond / 2
+}
+
+// sdReady notifies systemd that server is ready,
+// and starts watchdog keep-alive until returned func is called
+func sdReady() (stop func()) {
+	sdNotify("READY=1")
+
+	d := sdWatchdogInterval()
+	if d <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(d)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-t.C:
+				sdNotify("WATCHDOG=1")
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// sdStopping notifies systemd that graceful shutdown is started,
+// extends stop timeout to cover graceful shutdown's wait and timeout,
+// shutdown hooks have their own timeout
+func sdStopping(gs *GracefulShutdown, hooks bool) {
+	sdNotify("STOPPING=1")
+
+	if gs != nil && gs.timeout > 0 {
+		d := gs.wait + gs.timeout
+		if hooks {
+			d += gs.timeout
+		}
+		sdNotify("EXTEND_TIMEOUT_USEC=" + strconv.FormatInt(int64(d/time.Microsecond), 10))
+	}
+}
@@ -0,0 +1,192 @@
+//go:build linux
+// +build linux
+
+package hime
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetInheritedListeners() {
+	inherited.mu.Lock()
+	defer inherited.mu.Unlock()
+
+	for _, x := range inherited.list {
+		if x != nil {
+			x.ln.Close()
+		}
+	}
+	inherited.loaded = false
+	inherited.list = nil
+}
+
+// setEnv sets env and returns func to restore
+func setEnv(kv map[string]string) func() {
+	old := make(map[string]*string)
+	for k, v := range kv {
+		if x, ok := os.LookupEnv(k); ok {
+			old[k] = &x
+		} else {
+			old[k] = nil
+		}
+		os.Setenv(k, v)
+	}
+	return func() {
+		for k, v := range old {
+			if v == nil {
+				os.Unsetenv(k)
+			} else {
+				os.Setenv(k, *v)
+			}
+		}
+	}
+}
+
+// passListeners places listeners' fds at sdListenFDsStart like systemd does
+func passListeners(t *testing.T, names ...string) (addrs []string, restore func()) {
+	start := 200
+	for i := range names {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		f, err := ln.(*net.TCPListener).File()
+		require.NoError(t, err)
+		require.NoError(t, syscall.Dup3(int(f.Fd()), start+i, syscall.O_CLOEXEC))
+		addrs = append(addrs, ln.Addr().String())
+		f.Close()
+		ln.Close()
+	}
+
+	oldStart := sdListenFDsStart
+	sdListenFDsStart = start
+	resetInheritedListeners()
+	restoreEnv := setEnv(map[string]string{
+		"LISTEN_PID":     strconv.Itoa(os.Getpid()),
+		"LISTEN_FDS":     strconv.Itoa(len(names)),
+		"LISTEN_FDNAMES": strings.Join(names, ":"),
+	})
+	return addrs, func() {
+		restoreEnv()
+		resetInheritedListeners()
+		sdListenFDsStart = oldStart
+	}
+}
+
+func TestSystemdSocketActivation(t *testing.T) {
+	t.Run("App takes all listeners", func(t *testing.T) {
+		addrs, restore := passListeners(t, "web", "admin")
+		defer restore()
+
+		app := New().Address("127.0.0.1:0").Handler(stringHandler("ok"))
+		go app.ListenAndServe()
+		defer app.Shutdown(context.Background())
+		time.Sleep(100 * time.Millisecond)
+
+		assert.Equal(t, "ok", get(t, addrs[0]))
+		assert.Equal(t, "ok", get(t, addrs[1]))
+		assert.Empty(t, os.Getenv("LISTEN_FDS"))
+	})
+
+	t.Run("Apps match listener name", func(t *testing.T) {
+		addrs, restore := passListeners(t, "web", "admin")
+		defer restore()
+
+		app1 := New().Handler(stringHandler("web"))
+		app2 := New().ListenerName("admin").Handler(stringHandler("admin"))
+		apps := Merge(app1, app2)
+		go apps.ListenAndServe()
+		defer apps.Shutdown(context.Background())
+		time.Sleep(100 * time.Millisecond)
+
+		assert.Equal(t, "web", get(t, addrs[0]))
+		assert.Equal(t, "admin", get(t, addrs[1]))
+	})
+
+	t.Run("Other process", func(t *testing.T) {
+		_, restore := passListeners(t, "web")
+		defer restore()
+		os.Setenv("LISTEN_PID", "1")
+
+		lns, err := takeInheritedListeners("", 0)
+		assert.NoError(t, err)
+		assert.Empty(t, lns)
+		syscall.Close(sdListenFDsStart)
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		app := New().ParseConfig([]byte(`
+server:
+  listenerName: admin`))
+		assert.Equal(t, "admin", app.listenerName)
+		assert.Equal(t, "admin", app.Clone().listenerName)
+	})
+}
+
+func TestSystemdNotify(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: addr, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	restore := setEnv(map[string]string{
+		"NOTIFY_SOCKET": addr,
+		"WATCHDOG_USEC": "100000",
+		"WATCHDOG_PID":  strconv.Itoa(os.Getpid()),
+	})
+	defer restore()
+
+	read := func() string {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		b := make([]byte, 256)
+		n, err := conn.Read(b)
+		if err != nil {
+			return ""
+		}
+		return string(b[:n])
+	}
+
+	app := New().Address("127.0.0.1:0")
+	app.GracefulShutdown().Timeout(time.Second)
+	go app.ListenAndServe()
+
+	assert.Equal(t, "READY=1", read())
+	assert.Equal(t, "WATCHDOG=1", read())
+
+	go app.Shutdown(context.Background())
+
+	// skip pending watchdog
+	msg := read()
+	for msg == "WATCHDOG=1" {
+		msg = read()
+	}
+	assert.Equal(t, "STOPPING=1", msg)
+	assert.Equal(t, "EXTEND_TIMEOUT_USEC=1000000", read())
+
+	t.Run("Watchdog for other process", func(t *testing.T) {
+		os.Setenv("WATCHDOG_PID", "1")
+		assert.Equal(t, time.Duration(0), sdWatchdogInterval())
+	})
+
+	t.Run("Prefork child", func(t *testing.T) {
+		os.Setenv("HIME_PREFORK_CHILD", "1")
+		defer os.Unsetenv("HIME_PREFORK_CHILD")
+
+		assert.NoError(t, sdNotify("READY=1"))
+		assert.Equal(t, "", read())
+	})
+
+	t.Run("No notify socket", func(t *testing.T) {
+		os.Unsetenv("NOTIFY_SOCKET")
+		assert.NoError(t, sdNotify("READY=1"))
+	})
+}
@@ -1,28 +1,32 @@
 package hime
 
 import (
+	"fmt"
 	"html/template"
 	"io"
 	"io/fs"
-	"io/ioutil"
+	"os"
 	"path"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/tdewolff/minify/v2"
 	"github.com/tdewolff/minify/v2/css"
 	"github.com/tdewolff/minify/v2/html"
 	"github.com/tdewolff/minify/v2/js"
-	"gopkg.in/yaml.v3"
 )
 
 // TemplateConfig is template config
 type TemplateConfig struct {
-	Dir     string              `yaml:"dir" json:"dir"`
-	Root    string              `yaml:"root" json:"root"`
-	Minify  bool                `yaml:"minify" json:"minify"`
-	Preload []string            `yaml:"preload" json:"preload"`
-	List    map[string][]string `yaml:"list" json:"list"`
-	Delims  []string            `yaml:"delims" json:"delims"`
+	Dir        string              `yaml:"dir" json:"dir"`
+	Root       string              `yaml:"root" json:"root"`
+	Minify     bool                `yaml:"minify" json:"minify"`
+	Preload    []string            `yaml:"preload" json:"preload"`
+	Components string              `yaml:"components" json:"components"`
+	StdFuncs   bool                `yaml:"stdFuncs" json:"stdFuncs"`
+	List       map[string][]string `yaml:"list" json:"list"`
+	Delims     []string            `yaml:"delims" json:"delims"`
 }
 
 // Template creates new template loader
@@ -30,15 +34,47 @@ func (app *App) Template() *Template {
 	if app.template == nil {
 		app.template = make(map[string]*tmpl)
 	}
-	return &Template{
-		list:      app.template,
-		localList: make(map[string]*tmpl),
-		funcs: append([]template.FuncMap{{
-			"route":  app.Route,
-			"global": app.Global,
-		}}, app.templateFuncs...),
-		components: make(map[string]*template.Template),
+	funcs := append([]template.FuncMap{{
+		"route":  app.Route,
+		"global": app.Global,
+		"asset":  app.Asset,
+		"url":    app.RouteURL,
+	}}, app.templateFuncs...)
+
+	// url renders absolute url from request when bound to context,
+	// and from app's base url when render without context
+	ctxFuncs := ContextFuncMap{"url": urlFunc}
+	stubs := make(template.FuncMap)
+	for _, m := range app.contextFuncs {
+		for name, fn := range m {
+			ctxFuncs[name] = fn
+			stubs[name] = noContextFunc(name)
+		}
+	}
+	funcs = append(funcs, stubs)
+
+	ctxFuncNames := map[string]struct{}{"component": {}}
+	ctxReset := template.FuncMap{"url": app.RouteURL}
+	for name := range ctxFuncs {
+		ctxFuncNames[name] = struct{}{}
+	}
+	for name, fn := range stubs {
+		ctxReset[name] = fn
 	}
+
+	tp := &Template{
+		app:            app,
+		list:           app.template,
+		localList:      make(map[string]*tmpl),
+		funcs:          funcs,
+		ctxFuncs:       ctxFuncs,
+		ctxFuncNames:   ctxFuncNames,
+		components:     make(map[string]*template.Template),
+		componentPools: make(map[string]*sync.Pool),
+	}
+	ctxReset["component"] = tp.renderComponent
+	tp.ctxReset = ctxReset
+	return tp
 }
 
 // TemplateFuncs registers app's level template funcs
@@ -52,43 +88,121 @@ func (app *App) TemplateFunc(name string, f interface{}) *App {
 	return app.TemplateFuncs(template.FuncMap{name: f})
 }
 
+// ContextFuncMap is the map of template func name to func
+// that creates template func bound to request's context
+type ContextFuncMap map[string]func(ctx *Context) interface{}
+
+// ContextFuncs registers app's level template funcs that bind to request's context
+// when ctx.View renders template,
+// funcs return ErrNoContext when render without request's context
+//
+//	app.ContextFuncs(hime.ContextFuncMap{
+//		"path": func(ctx *hime.Context) interface{} {
+//			return func() string { return ctx.URL.Path }
+//		},
+//	})
+//
+// ContextFuncs must call before load template
+func (app *App) ContextFuncs(funcs ...ContextFuncMap) *App {
+	app.contextFuncs = append(app.contextFuncs, funcs...)
+	return app
+}
+
+// ContextFunc registers an app's level template func that binds to request's context
+func (app *App) ContextFunc(name string, fn func(ctx *Context) interface{}) *App {
+	return app.ContextFuncs(ContextFuncMap{name: fn})
+}
+
+// noContextFunc returns template func that always returns ErrNoContext
+func noContextFunc(name string) func(...interface{}) (interface{}, error) {
+	return func(...interface{}) (interface{}, error) {
+		return nil, newErrNoContext(name)
+	}
+}
+
 type tmpl struct {
 	*template.Template
-	m *minify.M
+	m          *minify.M
+	components map[string]*template.Template
+
+	// pool holds clones that bind to request's context,
+	// pool is nil when template does not use any context funcs
+	tp   *Template
+	pool *sync.Pool
 }
 
 func (t *tmpl) Execute(w io.Writer, data interface{}) error {
+	return t.execute(t.Template, w, data, nil)
+}
+
+// ExecuteContext executes template with context funcs bound to ctx
+func (t *tmpl) ExecuteContext(ctx *Context, w io.Writer, data interface{}) error {
+	return t.executeContext(ctx, w, data, nil)
+}
+
+func (t *tmpl) executeContext(ctx *Context, w io.Writer, data interface{}, st *RenderStats) error {
+	if t.pool == nil || ctx == nil {
+		return t.execute(t.Template, w, data, st)
+	}
+
+	x := t.pool.Get().(*template.Template)
+	defer t.pool.Put(x)
+	defer t.tp.unbindContext(x)
+
+	t.tp.bindContext(x, ctx)
+	return t.execute(x, w, data, st)
+}
+
+// execute executes x then minify if enabled,
+// records execute and minify time into st if st is not nil
+func (t *tmpl) execute(x *template.Template, w io.Writer, data interface{}, st *RenderStats) error {
 	// t.m.Writer is too slow for short data (html)
 
+	start := time.Now()
 	if t.m == nil {
-		return t.Template.Execute(w, data)
+		err := x.Execute(w, data)
+		if st != nil {
+			st.ExecuteTime = time.Since(start)
+		}
+		return err
 	}
 
 	buf := getBytes()
 	defer putBytes(buf)
 
-	err := t.Template.Execute(buf, data)
+	err := x.Execute(buf, data)
+	if st != nil {
+		st.ExecuteTime = time.Since(start)
+	}
 	if err != nil {
 		return err
 	}
 
-	return t.m.Minify("text/html", w, buf)
+	start = time.Now()
+	err = t.m.Minify("text/html", w, buf)
+	if st != nil {
+		st.MinifyTime = time.Since(start)
+	}
+	return err
 }
 
 // Template is template loader
 type Template struct {
+	app        *App
 	parent     *template.Template
 	list       map[string]*tmpl
 	localList  map[string]*tmpl
-	root       string
-	fs         fs.FS
-	dir        string
-	leftDelim  string
-	rightDelim string
 	funcs      []template.FuncMap
 	components map[string]*template.Template
 	minifier   *minify.M
-	parsed     bool
+	templateLoader
+
+	// ctxFuncNames are funcs that rebind on every render with context,
+	// ctxReset restores them before clone returns to pool
+	ctxFuncs       ContextFuncMap
+	ctxFuncNames   map[string]struct{}
+	ctxReset       template.FuncMap
+	componentPools map[string]*sync.Pool
 }
 
 func (tp *Template) init() {
@@ -99,6 +213,10 @@ func (tp *Template) init() {
 				"param":        tfParam,
 				"templateName": tfTemplateName,
 				"component":    tp.renderComponent,
+				"slot":         tfSlot,
+				"cache":        tfCache,
+				"cacheKey":     tfCacheKey,
+				"dict":         tfDict,
 			})
 
 		// register funcs
@@ -110,15 +228,17 @@ func (tp *Template) init() {
 
 // Config loads template config
 func (tp *Template) Config(cfg TemplateConfig) *Template {
-	tp.Dir(cfg.Dir)
-	tp.Root(cfg.Root)
-	if len(cfg.Delims) == 2 {
-		tp.Delims(cfg.Delims[0], cfg.Delims[1])
-	}
+	tp.config(cfg.Dir, cfg.Root, cfg.Delims)
 	if cfg.Minify {
 		tp.Minify()
 	}
+	if cfg.StdFuncs {
+		tp.StdFuncs()
+	}
 	tp.Preload(cfg.Preload...)
+	if cfg.Components != "" {
+		tp.ComponentDir(cfg.Components)
+	}
 	for name, filenames := range cfg.List {
 		tp.ParseFiles(name, filenames...)
 	}
@@ -129,20 +249,13 @@ func (tp *Template) Config(cfg TemplateConfig) *Template {
 // ParseConfig parses template config data
 func (tp *Template) ParseConfig(data []byte) *Template {
 	var config TemplateConfig
-	err := yaml.Unmarshal(data, &config)
-	if err != nil {
-		panicf("can not parse template config; %v", err)
-	}
+	parseTemplateConfig(data, &config)
 	return tp.Config(config)
 }
 
 // ParseConfigFile parses template config from file
 func (tp *Template) ParseConfigFile(filename string) *Template {
-	data, err := ioutil.ReadFile(filename)
-	if err != nil {
-		panicf("read template config file; %v", err)
-	}
-	return tp.ParseConfig(data)
+	return tp.ParseConfig(readTemplateConfigFile(filename))
 }
 
 type TemplateMinifyConfig struct {
@@ -224,57 +337,98 @@ func (tp *Template) Func(name string, f interface{}) *Template {
 
 // Preload loads given templates before every templates
 func (tp *Template) Preload(filename ...string) *Template {
-	if tp.parsed {
-		panicf("preload must call before parse")
-	}
-	if len(filename) == 0 {
-		return tp
-	}
-
 	tp.init()
-	if tp.fs == nil {
-		template.Must(tp.parent.ParseFiles(joinTemplateDir(tp.dir, filename...)...))
-	} else {
-		template.Must(tp.parent.ParseFS(tp.fs, joinTemplateDir(tp.dir, filename...)...))
-	}
-
+	tp.preload(htmlSet{tp.parent}, filename)
 	return tp
 }
 
-func (tp *Template) newTemplate(name string, parser func(t *template.Template) *template.Template) {
+func (tp *Template) newTemplate(name string, parser func(t templateSet) templateSet) {
 	if _, ok := tp.list[name]; ok {
 		panic(newErrTemplateDuplicate(name))
 	}
 
 	tp.init()
 
-	t := template.Must(tp.parent.Clone()).
-		Funcs(template.FuncMap{
-			"templateName": func() string { return name },
+	set := template.Must(tp.parent.Clone())
+	set.Funcs(tp.pageFuncs(name, set))
+	t := tp.lookupRoot(parser(htmlSet{set})).(htmlSet).Template
+
+	x := &tmpl{
+		Template:   t,
+		m:          tp.minifier,
+		components: tp.components,
+	}
+	if usesFuncs(t, tp.ctxFuncNames) {
+		x.tp = tp
+		x.pool = newTemplatePool(t, func(c *template.Template) {
+			c.Funcs(tp.pageFuncs(name, c))
 		})
+	}
 
-	t = parser(t)
+	tp.list[name] = x
+	tp.localList[name] = x
+	tp.parsed = true
+}
 
-	if tp.root != "" {
-		t = t.Lookup(tp.root)
+// newTemplatePool creates pool of t's clones,
+// setup is called on every new clone if not nil
+func newTemplatePool(t *template.Template, setup func(c *template.Template)) *sync.Pool {
+	// clone before execute, html/template can not clone after executed
+	src, err := t.Clone()
+	if err != nil {
+		panicf("clone template '%s'; %v", t.Name(), err)
 	}
+	var mu sync.Mutex
+
+	return &sync.Pool{
+		New: func() interface{} {
+			mu.Lock()
+			defer mu.Unlock()
+
+			c := template.Must(src.Clone())
+			if setup != nil {
+				setup(c)
+			}
+			return c
+		},
+	}
+}
 
-	if t == nil {
-		panicf("no root layout")
+// bindContext binds context funcs on pooled clone x to ctx
+func (tp *Template) bindContext(x *template.Template, ctx *Context) {
+	m := make(template.FuncMap, len(tp.ctxFuncs)+1)
+	for name, fn := range tp.ctxFuncs {
+		m[name] = fn(ctx)
+	}
+	m["component"] = func(name string, args ...interface{}) (template.HTML, error) {
+		return tp.renderComponentContext(ctx, name, args)
 	}
+	x.Funcs(m)
+}
 
-	tp.list[name] = &tmpl{
-		Template: t,
-		m:        tp.minifier,
+// unbindContext resets context funcs on x,
+// so clone in pool does not hold previous request's context
+func (tp *Template) unbindContext(x *template.Template) {
+	x.Funcs(tp.ctxReset)
+}
+
+// pageFuncs returns funcs that bind to page template's set
+func (tp *Template) pageFuncs(name string, set *template.Template) template.FuncMap {
+	return template.FuncMap{
+		"templateName": func() string { return name },
+		"slot": func(slotName string, data ...interface{}) (template.HTML, error) {
+			return executeHTML(set, slotName, data)
+		},
+		"cache": func(key string, ttl interface{}, fragmentName string, data ...interface{}) (template.HTML, error) {
+			return renderCachedFragment(tp.app.getFragmentCache(), set, name, key, ttl, fragmentName, data)
+		},
 	}
-	tp.localList[name] = tp.list[name]
-	tp.parsed = true
 }
 
 // Parse parses template from text
 func (tp *Template) Parse(name string, text string) *Template {
-	tp.newTemplate(name, func(t *template.Template) *template.Template {
-		return template.Must(t.New(name).Parse(text))
+	tp.newTemplate(name, func(t templateSet) templateSet {
+		return tp.parse(t, name, text)
 	})
 
 	return tp
@@ -282,16 +436,8 @@ func (tp *Template) Parse(name string, text string) *Template {
 
 // ParseFiles loads template from file
 func (tp *Template) ParseFiles(name string, filenames ...string) *Template {
-	tp.newTemplate(name, func(t *template.Template) *template.Template {
-		if tp.fs == nil {
-			t = template.Must(t.ParseFiles(joinTemplateDir(tp.dir, filenames...)...))
-		} else {
-			t = template.Must(t.ParseFS(tp.fs, joinTemplateDir(tp.dir, filenames...)...))
-		}
-		if tp.root == "" {
-			t = t.Lookup(filenames[0])
-		}
-		return t
+	tp.newTemplate(name, func(t templateSet) templateSet {
+		return tp.parseFiles(t, filenames)
 	})
 
 	return tp
@@ -299,20 +445,8 @@ func (tp *Template) ParseFiles(name string, filenames ...string) *Template {
 
 // ParseGlob loads template from pattern
 func (tp *Template) ParseGlob(name string, pattern string) *Template {
-	if tp.root == "" {
-		panicf("parse glob can not use without root")
-	}
-
-	tp.newTemplate(name, func(t *template.Template) *template.Template {
-		d := tp.dir
-		if !strings.HasSuffix(d, "/") {
-			d += "/"
-		}
-		if tp.fs == nil {
-			return template.Must(t.ParseGlob(d + pattern))
-		} else {
-			return template.Must(t.ParseFS(tp.fs, d+pattern))
-		}
+	tp.newTemplate(name, func(t templateSet) templateSet {
+		return tp.parseGlob(t, pattern)
 	})
 
 	return tp
@@ -331,35 +465,128 @@ func (tp *Template) Component(ts ...*template.Template) *Template {
 		}
 
 		tp.components[name] = t
+		if usesFuncs(t, tp.ctxFuncNames) {
+			tp.componentPools[name] = newTemplatePool(t, nil)
+		}
+	}
+
+	return tp
+}
+
+// ComponentDir loads every file in given directory as component,
+// components are parsed with template's funcs and delims,
+// and named by file path without extension
+//
+// Example:
+//
+// comp/button.tmpl:
+//
+//	<a href="{{.href}}">{{.label}}{{.slot}}</a>
+//
+// page:
+//
+//	{{define "icon"}}<i>+</i>{{end}}
+//	{{component "button" (dict "href" "/new" "label" "New" "slot" (slot "icon"))}}
+func (tp *Template) ComponentDir(dir string) *Template {
+	tp.init()
+
+	root := path.Join(tp.dir, dir)
+	var fsys fs.FS
+	if tp.fs == nil {
+		fsys = os.DirFS(root)
+	} else {
+		var err error
+		fsys, err = fs.Sub(tp.fs, root)
+		if err != nil {
+			panicf("load component dir; %v", err)
+		}
+	}
+
+	err := fs.WalkDir(fsys, ".", func(filename string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		b, err := fs.ReadFile(fsys, filename)
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimSuffix(filename, path.Ext(filename))
+		t, err := template.Must(tp.parent.Clone()).New(name).Parse(string(b))
+		if err != nil {
+			return err
+		}
+		tp.Component(t)
+		return nil
+	})
+	if err != nil {
+		panicf("load component dir; %v", err)
 	}
 
 	return tp
 }
 
-func (tp *Template) renderComponent(name string, args ...interface{}) template.HTML {
+func (tp *Template) renderComponent(name string, args ...interface{}) (template.HTML, error) {
 	t := tp.components[name]
 	if t == nil {
-		panicf("component '%s' not found", name)
+		return "", fmt.Errorf("hime: component '%s' not found", name)
 	}
 
-	var d interface{}
+	return executeHTML(t, "", args)
+}
+
+// renderComponentContext renders component with context funcs bound to ctx
+func (tp *Template) renderComponentContext(ctx *Context, name string, args []interface{}) (template.HTML, error) {
+	p := tp.componentPools[name]
+	if p == nil {
+		return tp.renderComponent(name, args...)
+	}
+
+	x := p.Get().(*template.Template)
+	defer p.Put(x)
+	defer tp.unbindContext(x)
+
+	tp.bindContext(x, ctx)
+	return executeHTML(x, "", args)
+}
+
+// componentData converts component args into template data,
+// named args must pass through dict
+func componentData(args []interface{}) (interface{}, error) {
 	switch len(args) {
 	case 0:
+		return nil, nil
 	case 1:
-		d = args[0]
+		return args[0], nil
 	default:
-		panicf("wrong number of data args for component want 0-1 got %d", len(args))
+		return nil, fmt.Errorf("hime: wrong number of data args want 0-1 got %d", len(args))
+	}
+}
+
+// executeHTML executes template t, or named template in t's set if name is not empty
+func executeHTML(t *template.Template, name string, args []interface{}) (template.HTML, error) {
+	d, err := componentData(args)
+	if err != nil {
+		return "", err
 	}
 
 	buf := getBytes()
 	defer putBytes(buf)
 
-	err := t.Execute(buf, d)
+	if name == "" {
+		err = t.Execute(buf, d)
+	} else {
+		err = t.ExecuteTemplate(buf, name, d)
+	}
 	if err != nil {
-		panic(err)
+		return "", err
 	}
 
-	return template.HTML(buf.String())
+	return template.HTML(buf.String()), nil
 }
 
 func joinTemplateDir(dir string, filenames ...string) []string {
@@ -382,6 +609,16 @@ func cloneFuncMaps(xs []template.FuncMap) []template.FuncMap {
 	return rs
 }
 
+func cloneContextFuncs(xs []ContextFuncMap) []ContextFuncMap {
+	if xs == nil {
+		return nil
+	}
+
+	rs := make([]ContextFuncMap, len(xs))
+	copy(rs, xs)
+	return rs
+}
+
 func cloneTmpl(xs map[string]*tmpl) map[string]*tmpl {
 	if xs == nil {
 		return nil
@@ -401,3 +638,7 @@ func tfParam(name string, value interface{}) *Param {
 func tfTemplateName() string {
 	return ""
 }
+
+func tfSlot(name string, data ...interface{}) (template.HTML, error) {
+	return "", fmt.Errorf("hime: slot '%s' can not use outside page template", name)
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"embed"
 	"html/template"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -73,6 +75,19 @@ list:
 		assert.NotContains(t, tp.list, "p1.tmpl")
 	})
 
+	t.Run("ParseConfig with components", func(t *testing.T) {
+		tp := New().Template()
+		tp.ParseConfig([]byte(`
+dir: testdata/template
+delims: ["[[", "]]"]
+components: comp-delims
+list:
+  k: [k1.tmpl]`))
+
+		assert.Contains(t, tp.components, "hello")
+		assert.Contains(t, tp.list, "k")
+	})
+
 	t.Run("ParseConfig invalid", func(t *testing.T) {
 		tp := New().Template()
 		assert.Panics(t, func() { tp.ParseConfig([]byte(`invalidyamlbytes`)) })
@@ -240,6 +255,95 @@ list:
 		}
 	})
 
+	t.Run("Component with named args", func(t *testing.T) {
+		tp := New().Template()
+		tp.Component(template.Must(template.New("c").Parse(`{{.a}}, {{.b}}`)))
+		tp.Parse("t", `Test Data {{component "c" (dict "a" "hello" "b" 1)}}`)
+
+		if assert.Contains(t, tp.list, "t") {
+			b := bytes.Buffer{}
+			if assert.NoError(t, tp.list["t"].Execute(&b, nil)) {
+				assert.Equal(t, b.String(), "Test Data hello, 1")
+			}
+		}
+	})
+
+	t.Run("Component with invalid named args", func(t *testing.T) {
+		tp := New().Template()
+		tp.Component(template.Must(template.New("c").Parse(`{{.a}}`)))
+		tp.Parse("t", `Test Data {{component "c" (dict 1 "a")}}`)
+
+		if assert.Contains(t, tp.list, "t") {
+			b := bytes.Buffer{}
+			assert.Error(t, tp.list["t"].Execute(&b, nil))
+		}
+	})
+
+	t.Run("ComponentDir", func(t *testing.T) {
+		app := New()
+		app.Routes(Routes{"index": "/"})
+		tp := app.Template()
+		tp.Dir("testdata/template")
+		tp.ComponentDir("comp")
+		tp.Parse("t", `{{define "icon"}}<i>{{.}}</i>{{end}}{{component "button" (dict "label" "Home" "slot" (slot "icon" "x"))}}`)
+
+		assert.Contains(t, tp.components, "button")
+		assert.Contains(t, tp.components, "form/input")
+		if assert.Contains(t, tp.list, "t") {
+			b := bytes.Buffer{}
+			if assert.NoError(t, tp.list["t"].Execute(&b, nil)) {
+				assert.Equal(t, b.String(), `<a href="/">Home<i>x</i></a>`)
+			}
+		}
+	})
+
+	t.Run("ComponentDir using FS", func(t *testing.T) {
+		tp := New().Template()
+		tp.FS(testTemplateFS)
+		tp.Dir("testdata/template")
+		tp.Delims("[[", "]]")
+		tp.ComponentDir("comp-delims")
+		tp.Parse("t", `[[component "hello" (dict "name" "hime")]]`)
+
+		if assert.Contains(t, tp.list, "t") {
+			b := bytes.Buffer{}
+			if assert.NoError(t, tp.list["t"].Execute(&b, nil)) {
+				assert.Equal(t, b.String(), `hello, hime`)
+			}
+		}
+	})
+
+	t.Run("ComponentDir not exists", func(t *testing.T) {
+		tp := New().Template()
+		tp.Dir("testdata/template")
+		assert.Panics(t, func() { tp.ComponentDir("not-exists") })
+	})
+
+	t.Run("slot outside page template", func(t *testing.T) {
+		tp := New().Template()
+		tp.Component(template.Must(template.New("c").Funcs(template.FuncMap{"slot": tfSlot}).Parse(`{{slot "x"}}`)))
+		tp.Parse("t", `{{component "c"}}`)
+
+		b := bytes.Buffer{}
+		assert.Error(t, tp.list["t"].Execute(&b, nil))
+	})
+
+	t.Run("Pooled template resets context funcs", func(t *testing.T) {
+		app := New()
+		app.ContextFunc("path", func(ctx *Context) interface{} {
+			return func() string { return ctx.URL.Path }
+		})
+		app.Template().Parse("t", `{{path}}`)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/a", nil)
+		assert.NoError(t, NewAppContext(app, w, r).View("t", nil))
+
+		x := app.template["t"].pool.Get().(*template.Template)
+		b := bytes.Buffer{}
+		assert.Error(t, x.Execute(&b, nil))
+	})
+
 	t.Run("Component not exists", func(t *testing.T) {
 		tp := New().Template()
 		tp.Parse("t", `Test Data {{component "c"}}`)
@@ -0,0 +1,187 @@
+package hime
+
+import (
+	htmltemplate "html/template"
+	"io/fs"
+	"io/ioutil"
+	"strings"
+	texttemplate "text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// templateSet is the parse api that html/template and text/template share
+type templateSet interface {
+	parse(name, text string) (templateSet, error)
+	parseFiles(filenames ...string) (templateSet, error)
+	parseFS(fsys fs.FS, patterns ...string) (templateSet, error)
+	parseGlob(pattern string) (templateSet, error)
+	lookup(name string) templateSet
+}
+
+type htmlSet struct {
+	*htmltemplate.Template
+}
+
+func (s htmlSet) parse(name, text string) (templateSet, error) {
+	t, err := s.New(name).Parse(text)
+	return htmlSet{t}, err
+}
+
+func (s htmlSet) parseFiles(filenames ...string) (templateSet, error) {
+	t, err := s.ParseFiles(filenames...)
+	return htmlSet{t}, err
+}
+
+func (s htmlSet) parseFS(fsys fs.FS, patterns ...string) (templateSet, error) {
+	t, err := s.ParseFS(fsys, patterns...)
+	return htmlSet{t}, err
+}
+
+func (s htmlSet) parseGlob(pattern string) (templateSet, error) {
+	t, err := s.ParseGlob(pattern)
+	return htmlSet{t}, err
+}
+
+func (s htmlSet) lookup(name string) templateSet {
+	t := s.Lookup(name)
+	if t == nil {
+		return nil
+	}
+	return htmlSet{t}
+}
+
+type textSet struct {
+	*texttemplate.Template
+}
+
+func (s textSet) parse(name, text string) (templateSet, error) {
+	t, err := s.New(name).Parse(text)
+	return textSet{t}, err
+}
+
+func (s textSet) parseFiles(filenames ...string) (templateSet, error) {
+	t, err := s.ParseFiles(filenames...)
+	return textSet{t}, err
+}
+
+func (s textSet) parseFS(fsys fs.FS, patterns ...string) (templateSet, error) {
+	t, err := s.ParseFS(fsys, patterns...)
+	return textSet{t}, err
+}
+
+func (s textSet) parseGlob(pattern string) (templateSet, error) {
+	t, err := s.ParseGlob(pattern)
+	return textSet{t}, err
+}
+
+func (s textSet) lookup(name string) templateSet {
+	t := s.Lookup(name)
+	if t == nil {
+		return nil
+	}
+	return textSet{t}
+}
+
+// templateLoader is the loading logic that Template and TextTemplate share
+type templateLoader struct {
+	root       string
+	fs         fs.FS
+	dir        string
+	leftDelim  string
+	rightDelim string
+	parsed     bool
+}
+
+// config loads config that every template engine has
+func (l *templateLoader) config(dir, root string, delims []string) {
+	l.dir = dir
+	l.root = root
+	if len(delims) == 2 {
+		l.leftDelim = delims[0]
+		l.rightDelim = delims[1]
+	}
+}
+
+// preload parses filenames into parent set
+func (l *templateLoader) preload(parent templateSet, filenames []string) {
+	if l.parsed {
+		panicf("preload must call before parse")
+	}
+	if len(filenames) == 0 {
+		return
+	}
+
+	if l.fs == nil {
+		mustTemplateSet(parent.parseFiles(joinTemplateDir(l.dir, filenames...)...))
+	} else {
+		mustTemplateSet(parent.parseFS(l.fs, joinTemplateDir(l.dir, filenames...)...))
+	}
+}
+
+func (l *templateLoader) parse(t templateSet, name, text string) templateSet {
+	return mustTemplateSet(t.parse(name, text))
+}
+
+func (l *templateLoader) parseFiles(t templateSet, filenames []string) templateSet {
+	if l.fs == nil {
+		t = mustTemplateSet(t.parseFiles(joinTemplateDir(l.dir, filenames...)...))
+	} else {
+		t = mustTemplateSet(t.parseFS(l.fs, joinTemplateDir(l.dir, filenames...)...))
+	}
+	if l.root == "" {
+		t = t.lookup(filenames[0])
+	}
+	return t
+}
+
+func (l *templateLoader) parseGlob(t templateSet, pattern string) templateSet {
+	if l.root == "" {
+		panicf("parse glob can not use without root")
+	}
+
+	d := l.dir
+	if !strings.HasSuffix(d, "/") {
+		d += "/"
+	}
+	if l.fs == nil {
+		return mustTemplateSet(t.parseGlob(d + pattern))
+	}
+	return mustTemplateSet(t.parseFS(l.fs, d+pattern))
+}
+
+// lookupRoot returns root layout of t
+func (l *templateLoader) lookupRoot(t templateSet) templateSet {
+	if t != nil && l.root != "" {
+		t = t.lookup(l.root)
+	}
+
+	if t == nil {
+		panicf("no root layout")
+	}
+	return t
+}
+
+func mustTemplateSet(t templateSet, err error) templateSet {
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// parseTemplateConfig unmarshals template config data into config
+func parseTemplateConfig(data []byte, config interface{}) {
+	err := yaml.Unmarshal(data, config)
+	if err != nil {
+		panicf("can not parse template config; %v", err)
+	}
+}
+
+// readTemplateConfigFile reads template config file
+func readTemplateConfigFile(filename string) []byte {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		panicf("read template config file; %v", err)
+	}
+	return data
+}
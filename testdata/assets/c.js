@@ -0,0 +1 @@
+var c = 1
@@ -0,0 +1,311 @@
+package hime
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"text/template"
+)
+
+// TextTemplateConfig is text template config
+type TextTemplateConfig struct {
+	Dir     string              `yaml:"dir" json:"dir"`
+	Root    string              `yaml:"root" json:"root"`
+	Preload []string            `yaml:"preload" json:"preload"`
+	List    map[string][]string `yaml:"list" json:"list"`
+	Delims  []string            `yaml:"delims" json:"delims"`
+}
+
+// TextTemplate creates new text template loader,
+// text templates use text/template for non-html output such as emails and plaintext
+func (app *App) TextTemplate() *TextTemplate {
+	if app.textTemplate == nil {
+		app.textTemplate = make(map[string]*template.Template)
+	}
+	funcs := []template.FuncMap{{
+		"route":  app.Route,
+		"global": app.Global,
+		"asset":  app.Asset,
+	}}
+	for _, fn := range app.templateFuncs {
+		funcs = append(funcs, template.FuncMap(fn))
+	}
+	return &TextTemplate{
+		list:  app.textTemplate,
+		funcs: funcs,
+	}
+}
+
+// TextTemplate is text template loader
+type TextTemplate struct {
+	parent *template.Template
+	list   map[string]*template.Template
+	funcs  []template.FuncMap
+	templateLoader
+}
+
+func (tp *TextTemplate) init() {
+	if tp.parent == nil {
+		tp.parent = template.New("").
+			Delims(tp.leftDelim, tp.rightDelim).
+			Funcs(template.FuncMap{
+				"param":        tfParam,
+				"templateName": tfTemplateName,
+			})
+
+		// register funcs
+		for _, fn := range tp.funcs {
+			tp.parent.Funcs(fn)
+		}
+	}
+}
+
+// Config loads text template config
+func (tp *TextTemplate) Config(cfg TextTemplateConfig) *TextTemplate {
+	tp.config(cfg.Dir, cfg.Root, cfg.Delims)
+	tp.Preload(cfg.Preload...)
+	for name, filenames := range cfg.List {
+		tp.ParseFiles(name, filenames...)
+	}
+
+	return tp
+}
+
+// ParseConfig parses text template config data
+func (tp *TextTemplate) ParseConfig(data []byte) *TextTemplate {
+	var config TextTemplateConfig
+	parseTemplateConfig(data, &config)
+	return tp.Config(config)
+}
+
+// ParseConfigFile parses text template config from file
+func (tp *TextTemplate) ParseConfigFile(filename string) *TextTemplate {
+	return tp.ParseConfig(readTemplateConfigFile(filename))
+}
+
+// Delims sets left and right delims
+func (tp *TextTemplate) Delims(left, right string) *TextTemplate {
+	tp.leftDelim = left
+	tp.rightDelim = right
+	return tp
+}
+
+// Root calls t.Lookup(name) after load template,
+// empty string won't trigger t.Lookup
+//
+// default is ""
+func (tp *TextTemplate) Root(name string) *TextTemplate {
+	tp.root = name
+	return tp
+}
+
+// Dir sets root directory when load template
+//
+// default is ""
+func (tp *TextTemplate) Dir(path string) *TextTemplate {
+	tp.dir = path
+	return tp
+}
+
+// FS uses fs when load template
+func (tp *TextTemplate) FS(fs fs.FS) *TextTemplate {
+	tp.fs = fs
+	return tp
+}
+
+// Funcs adds template funcs while load template
+func (tp *TextTemplate) Funcs(funcs ...template.FuncMap) *TextTemplate {
+	tp.funcs = append(tp.funcs, funcs...)
+	return tp
+}
+
+// Func adds a template func while load template
+func (tp *TextTemplate) Func(name string, f interface{}) *TextTemplate {
+	return tp.Funcs(template.FuncMap{name: f})
+}
+
+// Preload loads given templates before every templates
+func (tp *TextTemplate) Preload(filename ...string) *TextTemplate {
+	tp.init()
+	tp.preload(textSet{tp.parent}, filename)
+	return tp
+}
+
+func (tp *TextTemplate) newTemplate(name string, parser func(t templateSet) templateSet) {
+	if _, ok := tp.list[name]; ok {
+		panic(newErrTemplateDuplicate(name))
+	}
+
+	tp.init()
+
+	t := template.Must(tp.parent.Clone()).
+		Funcs(template.FuncMap{
+			"templateName": func() string { return name },
+		})
+
+	tp.list[name] = tp.lookupRoot(parser(textSet{t})).(textSet).Template
+	tp.parsed = true
+}
+
+// Parse parses template from text
+func (tp *TextTemplate) Parse(name string, text string) *TextTemplate {
+	tp.newTemplate(name, func(t templateSet) templateSet {
+		return tp.parse(t, name, text)
+	})
+
+	return tp
+}
+
+// ParseFiles loads template from file
+func (tp *TextTemplate) ParseFiles(name string, filenames ...string) *TextTemplate {
+	tp.newTemplate(name, func(t templateSet) templateSet {
+		return tp.parseFiles(t, filenames)
+	})
+
+	return tp
+}
+
+// ParseGlob loads template from pattern
+func (tp *TextTemplate) ParseGlob(name string, pattern string) *TextTemplate {
+	tp.newTemplate(name, func(t templateSet) templateSet {
+		return tp.parseGlob(t, pattern)
+	})
+
+	return tp
+}
+
+// RenderText renders text template into w
+func (app *App) RenderText(w io.Writer, name string, data interface{}) error {
+	t, ok := app.textTemplate[name]
+	if !ok {
+		return newErrTemplateNotFound(name)
+	}
+	return t.Execute(w, data)
+}
+
+// RenderString renders text template into string
+func (app *App) RenderString(name string, data interface{}) (string, error) {
+	buf := getBytes()
+	defer putBytes(buf)
+
+	err := app.RenderText(buf, name, data)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RenderView renders view into w without request,
+// view data funcs do not apply since there is no context,
+// and context funcs return ErrNoContext
+func (app *App) RenderView(w io.Writer, name string, data interface{}) error {
+	e := app.lookupView(name)
+	if e == nil {
+		return newErrTemplateNotFound(name)
+	}
+
+	m := app.getViewMinifier(e)
+	if m == nil {
+		return e.ExecuteView(nil, w, name, data)
+	}
+
+	buf := getBytes()
+	defer putBytes(buf)
+
+	err := e.ExecuteView(nil, buf, name, data)
+	if err != nil {
+		return err
+	}
+	return m.Minify("text/html", w, buf)
+}
+
+// RenderMultipart renders text template and view into multipart/alternative body,
+// returns body's content type with boundary,
+// view renders the same as RenderView
+func (app *App) RenderMultipart(w io.Writer, textName, viewName string, data interface{}) (string, error) {
+	buf := getBytes()
+	defer putBytes(buf)
+
+	err := app.RenderText(buf, textName, data)
+	if err != nil {
+		return "", err
+	}
+	text := append([]byte(nil), buf.Bytes()...)
+
+	buf.Reset()
+	err = app.RenderView(buf, viewName, data)
+	if err != nil {
+		return "", err
+	}
+
+	mw := multipart.NewWriter(w)
+	err = writeQuotedPrintablePart(mw, "text/plain; charset=utf-8", text)
+	if err != nil {
+		return "", err
+	}
+	err = writeQuotedPrintablePart(mw, "text/html; charset=utf-8", buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+	err = mw.Close()
+	if err != nil {
+		return "", err
+	}
+
+	return "multipart/alternative; boundary=" + mw.Boundary(), nil
+}
+
+func writeQuotedPrintablePart(mw *multipart.Writer, contentType string, b []byte) error {
+	pw, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+
+	qw := quotedprintable.NewWriter(pw)
+	_, err = io.Copy(qw, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	return qw.Close()
+}
+
+// Text renders text template
+func (ctx *Context) Text(name string, data interface{}) error {
+	t, ok := ctx.app.textTemplate[name]
+	if !ok {
+		return newErrTemplateNotFound(name)
+	}
+
+	buf := getBytes()
+	defer putBytes(buf)
+
+	err := t.Execute(buf, data)
+	if err != nil {
+		return err
+	}
+
+	if ctx.setETag(buf.Bytes()) {
+		return nil
+	}
+
+	ctx.setContentType("text/plain; charset=utf-8")
+	return ctx.CopyFrom(buf)
+}
+
+func cloneTextTmpl(xs map[string]*template.Template) map[string]*template.Template {
+	if xs == nil {
+		return nil
+	}
+
+	rs := make(map[string]*template.Template)
+	for k, v := range xs {
+		rs[k] = v
+	}
+	return rs
+}
@@ -0,0 +1,147 @@
+package hime
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextTemplate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ParseConfig", func(t *testing.T) {
+		app := New()
+		app.Routes(Routes{"index": "/"})
+		tp := app.TextTemplate()
+		tp.ParseConfig([]byte(`
+dir: testdata/text
+root: layout
+list:
+  welcome: [welcome.txt, _layout.txt]`))
+
+		assert.Equal(t, tp.dir, "testdata/text")
+		assert.Equal(t, tp.root, "layout")
+		assert.Contains(t, app.textTemplate, "welcome")
+	})
+
+	t.Run("ParseConfig invalid", func(t *testing.T) {
+		tp := New().TextTemplate()
+		assert.Panics(t, func() { tp.ParseConfig([]byte(`invalidyamlbytes`)) })
+	})
+
+	t.Run("App Config", func(t *testing.T) {
+		app := New().ParseConfig([]byte(`
+routes:
+  index: /
+textTemplates:
+- dir: testdata/text
+  root: layout
+  list:
+    welcome: [welcome.txt, _layout.txt]`))
+
+		assert.Contains(t, app.textTemplate, "welcome")
+	})
+
+	t.Run("RenderString", func(t *testing.T) {
+		app := New()
+		app.Routes(Routes{"index": "/"})
+		app.TextTemplate().Dir("testdata/text").Root("layout").ParseFiles("welcome", "welcome.txt", "_layout.txt")
+
+		s, err := app.RenderString("welcome", "user")
+		assert.NoError(t, err)
+		assert.Equal(t, "Hi user,\nWelcome to <hime> & /\n-- hime", s)
+
+		_, err = app.RenderString("not-exists", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("Preload and ParseGlob using FS", func(t *testing.T) {
+		app := New()
+		tp := app.TextTemplate()
+		tp.FS(testTemplateFS).Dir("testdata/template").Root("b")
+		tp.Preload("b.tmpl")
+		tp.ParseGlob("b", "p*.tmpl")
+
+		s, err := app.RenderString("b", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "b", s)
+		assert.Panics(t, func() { tp.Preload("b.tmpl") })
+	})
+
+	t.Run("Parse duplicate name", func(t *testing.T) {
+		tp := New().TextTemplate()
+		assert.NotPanics(t, func() { tp.Parse("t", "Test Data") })
+		assert.Panics(t, func() { tp.Parse("t", "Test Data") })
+	})
+
+	t.Run("Func", func(t *testing.T) {
+		app := New()
+		app.TemplateFunc("a", func() string { return "a" })
+		app.TextTemplate().Func("b", func() string { return "b" }).Parse("t", `{{a}}{{b}}{{templateName}}`)
+
+		s, err := app.RenderString("t", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "abt", s)
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		app := New()
+		app.TextTemplate().Parse("t", `<b>{{.}}</b>`)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		ctx := NewAppContext(app, w, r)
+		assert.NoError(t, ctx.Text("t", "hime"))
+		assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "<b>hime</b>", w.Body.String())
+
+		assert.IsType(t, &ErrTemplateNotFound{}, ctx.Text("not-exists", nil))
+	})
+
+	t.Run("RenderMultipart", func(t *testing.T) {
+		app := New()
+		app.TextTemplate().Parse("welcome", `Hello {{.}}`)
+		app.Template().Parse("welcome", `<p>Hello {{.}}</p>`)
+
+		var b bytes.Buffer
+		ct, err := app.RenderMultipart(&b, "welcome", "welcome", "<hime>")
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		mt, params, err := mime.ParseMediaType(ct)
+		assert.NoError(t, err)
+		assert.Equal(t, "multipart/alternative", mt)
+
+		mr := multipart.NewReader(&b, params["boundary"])
+
+		p, err := mr.NextPart()
+		if assert.NoError(t, err) {
+			assert.Equal(t, "text/plain; charset=utf-8", p.Header.Get("Content-Type"))
+			body, _ := ioutil.ReadAll(p)
+			assert.Equal(t, "Hello <hime>", string(body))
+		}
+
+		p, err = mr.NextPart()
+		if assert.NoError(t, err) {
+			assert.Equal(t, "text/html; charset=utf-8", p.Header.Get("Content-Type"))
+			body, _ := ioutil.ReadAll(p)
+			assert.Equal(t, "<p>Hello &lt;hime&gt;</p>", string(body))
+		}
+	})
+
+	t.Run("RenderMultipart template not exists", func(t *testing.T) {
+		app := New()
+		app.TextTemplate().Parse("welcome", `Hello`)
+
+		var b bytes.Buffer
+		_, err := app.RenderMultipart(&b, "welcome", "welcome", nil)
+		assert.Error(t, err)
+	})
+}
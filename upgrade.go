@@ -0,0 +1,239 @@
+package hime
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// upgradeReadyTimeout is the maximum time to wait new process to be ready
+var upgradeReadyTimeout = time.Minute
+
+// upgradeCommand returns command to start new process
+var upgradeCommand = func() (*exec.Cmd, error) {
+	p, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(p, os.Args[1:]...), nil
+}
+
+// Upgrade enables zero-downtime upgrade,
+// when receive upgrade signal, app starts new process from current executable
+// with app's listeners, then graceful shutdowns after new process is ready
+func (app *App) Upgrade(enable bool) *App {
+	app.upgrade = enable
+	return app
+}
+
+// UpgradeSignal sets signal that trigger upgrade
+//
+// default is SIGUSR2
+func (app *App) UpgradeSignal(sig os.Signal) *App {
+	app.upgradeSignal = sig
+	return app
+}
+
+func getUpgradeSignal(sig os.Signal) os.Signal {
+	if sig != nil {
+		return sig
+	}
+	if defaultUpgradeSignal == nil {
+		panicf("upgrade signal not supported")
+	}
+	return defaultUpgradeSignal
+}
+
+// parseSignal parses signal name (e.g. SIGUSR2, USR2)
+func parseSignal(name string) os.Signal {
+	name = strings.ToUpper(name)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	sig, ok := signalNames[name]
+	if !ok {
+		panicf("unknown signal '%s'", name)
+	}
+	return sig
+}
+
+// upgradeListener is the listener to pass to new process
+type upgradeListener struct {
+	name string
+	ln   net.Listener
+}
+
+// watchUpgrade starts new process when receive sig,
+// returned channel is closed when new process is ready
+func watchUpgrade(sig os.Signal, lns []upgradeListener) (upgraded <-chan struct{}, stop func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sig)
+
+	done := make(chan struct{})
+	ready := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ch:
+			case <-done:
+				return
+			}
+
+			pid, err := startUpgradeProcess(lns)
+			if err != nil {
+				log.Printf("hime: upgrade failed; %v", err)
+				continue
+			}
+
+			// new process becomes main process
+			sdNotify("MAINPID=" + strconv.Itoa(pid))
+			close(ready)
+			return
+		}
+	}()
+
+	return ready, func() {
+		signal.Stop(ch)
+		select {
+		case <-done:
+		default:
+			close(done)
+		}
+	}
+}
+
+type filer interface {
+	File() (*os.File, error)
+}
+
+// startUpgradeProcess starts new process with listeners and waits until it's ready
+func startUpgradeProcess(lns []upgradeListener) (int, error) {
+	var (
+		files []*os.File
+		names []string
+	)
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+
+	for _, x := range lns {
+		fl, ok := x.ln.(filer)
+		if !ok {
+			return 0, fmt.Errorf("listener %T can not pass to new process", x.ln)
+		}
+		f, err := fl.File()
+		if err != nil {
+			return 0, err
+		}
+		files = append(files, f)
+		names = append(names, x.name)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	cmd, err := upgradeCommand()
+	if err != nil {
+		w.Close()
+		return 0, err
+	}
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(upgradeEnv(cmd.Env),
+		"HIME_UPGRADE_FDS="+strconv.Itoa(len(files)),
+		"HIME_UPGRADE_FDNAMES="+strings.Join(names, ":"),
+		"HIME_UPGRADE_READY_FD="+strconv.Itoa(3+len(files)),
+	)
+	cmd.ExtraFiles = append(files, w)
+
+	err = cmd.Start()
+	w.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	readyChan := make(chan error, 1)
+	go func() {
+		b := make([]byte, 1)
+		_, err := r.Read(b)
+		readyChan <- err
+	}()
+
+	select {
+	case err = <-readyChan:
+	case <-time.After(upgradeReadyTimeout):
+		err = errors.New("timeout")
+	}
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return 0, fmt.Errorf("new process not ready; %v", err)
+	}
+
+	go cmd.Wait()
+	return cmd.Process.Pid, nil
+}
+
+// upgradeEnv removes WATCHDOG_PID from env,
+// new process sends watchdog keep-alive after becomes main process
+func upgradeEnv(env []string) []string {
+	xs := make([]string, 0, len(env))
+	for _, x := range env {
+		if strings.HasPrefix(x, "WATCHDOG_PID=") {
+			continue
+		}
+		xs = append(xs, x)
+	}
+	return xs
+}
+
+// upgradeReadyFile is the pipe to notify old process that new process is ready
+var upgradeReadyFile *os.File
+
+// loadUpgradeListeners loads listeners passed from old process
+func loadUpgradeListeners() error {
+	n, _ := strconv.Atoi(os.Getenv("HIME_UPGRADE_FDS"))
+	names := strings.Split(os.Getenv("HIME_UPGRADE_FDNAMES"), ":")
+	readyFD, _ := strconv.Atoi(os.Getenv("HIME_UPGRADE_READY_FD"))
+
+	os.Unsetenv("HIME_UPGRADE_FDS")
+	os.Unsetenv("HIME_UPGRADE_FDNAMES")
+	os.Unsetenv("HIME_UPGRADE_READY_FD")
+
+	if readyFD > 0 {
+		upgradeReadyFile = os.NewFile(uintptr(readyFD), "upgrade-ready")
+	}
+	return loadListenerFiles(3, n, names)
+}
+
+// upgradeReady notifies old process that new process is ready
+func upgradeReady() {
+	inherited.mu.Lock()
+	defer inherited.mu.Unlock()
+
+	if upgradeReadyFile == nil {
+		return
+	}
+	upgradeReadyFile.Write([]byte{1})
+	upgradeReadyFile.Close()
+	upgradeReadyFile = nil
+}
@@ -0,0 +1,163 @@
+//go:build linux
+// +build linux
+
+package hime
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUpgradeHelperProcess is the new process that started by upgrade
+func TestUpgradeHelperProcess(t *testing.T) {
+	if os.Getenv("HIME_TEST_UPGRADE_CHILD") != "1" {
+		return
+	}
+
+	New().Handler(stringHandler("child")).ListenAndServe()
+	os.Exit(0)
+}
+
+// useUpgradeHelper starts TestUpgradeHelperProcess as new process,
+// returns func to restore and kill new process,
+// upgrade waits new process itself
+func useUpgradeHelper() (restore func()) {
+	var child *exec.Cmd
+	oldCommand := upgradeCommand
+	upgradeCommand = func() (*exec.Cmd, error) {
+		child = exec.Command(os.Args[0], "-test.run=^TestUpgradeHelperProcess$")
+		child.Env = append(os.Environ(), "HIME_TEST_UPGRADE_CHILD=1")
+		return child, nil
+	}
+	return func() {
+		upgradeCommand = oldCommand
+		if child != nil && child.Process != nil {
+			child.Process.Kill()
+		}
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	resetInheritedListeners()
+	defer useUpgradeHelper()()
+
+	addr := freeAddr(t)
+	app := New().Address(addr).Handler(stringHandler("parent")).Upgrade(true)
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- app.ListenAndServe() }()
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, "parent", get(t, addr))
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR2))
+
+	select {
+	case err := <-errChan:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "old process not shutdown")
+	}
+	assert.Equal(t, "child", get(t, addr))
+}
+
+func TestUpgradeSystemd(t *testing.T) {
+	resetInheritedListeners()
+	defer useUpgradeHelper()()
+
+	sock := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: sock, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	restore := setEnv(map[string]string{
+		"NOTIFY_SOCKET": sock,
+		"WATCHDOG_USEC": "100000",
+		"WATCHDOG_PID":  strconv.Itoa(os.Getpid()),
+	})
+	defer restore()
+
+	var (
+		mu   sync.Mutex
+		msgs []string
+	)
+	go func() {
+		b := make([]byte, 256)
+		for {
+			n, err := conn.Read(b)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			msgs = append(msgs, string(b[:n]))
+			mu.Unlock()
+		}
+	}()
+	received := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), msgs...)
+	}
+
+	app := New().Address(freeAddr(t)).Handler(stringHandler("parent")).Upgrade(true)
+	app.GracefulShutdown().Timeout(time.Second)
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- app.ListenAndServe() }()
+	time.Sleep(100 * time.Millisecond)
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR2))
+
+	select {
+	case err := <-errChan:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "old process not shutdown")
+	}
+
+	// old process stopped its watchdog, keep-alive must come from new process
+	time.Sleep(100 * time.Millisecond)
+	n := len(received())
+	time.Sleep(300 * time.Millisecond)
+	xs := received()
+	assert.Contains(t, xs[n:], "WATCHDOG=1", "new process must send watchdog keep-alive")
+
+	var mainPID bool
+	for _, x := range xs {
+		mainPID = mainPID || strings.HasPrefix(x, "MAINPID=")
+	}
+	assert.True(t, mainPID, "old process must notify new main process")
+	assert.NotContains(t, xs, "STOPPING=1", "old process must not notify stopping")
+}
+
+func TestUpgradeConfig(t *testing.T) {
+	app := New().ParseConfig([]byte(`
+server:
+  upgrade: true
+  upgradeSignal: hup`))
+	assert.True(t, app.upgrade)
+	assert.Equal(t, syscall.SIGHUP, app.upgradeSignal)
+
+	x := app.Clone()
+	assert.True(t, x.upgrade)
+	assert.Equal(t, syscall.SIGHUP, x.upgradeSignal)
+
+	assert.Panics(t, func() { parseSignal("SIGFOO") })
+
+	apps := Merge(app)
+	apps.ParseConfig([]byte(`
+upgrade: true
+upgradeSignal: SIGUSR1`))
+	assert.True(t, apps.upgrade)
+	assert.Equal(t, syscall.SIGUSR1, apps.upgradeSignal)
+}
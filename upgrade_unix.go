@@ -0,0 +1,17 @@
+//go:build !windows
+// +build !windows
+
+package hime
+
+import (
+	"os"
+	"syscall"
+)
+
+var defaultUpgradeSignal os.Signal = syscall.SIGUSR2
+
+var signalNames = map[string]os.Signal{
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+}
@@ -0,0 +1,13 @@
+package hime
+
+import (
+	"os"
+	"syscall"
+)
+
+// windows can not pass listeners to new process
+var defaultUpgradeSignal os.Signal
+
+var signalNames = map[string]os.Signal{
+	"SIGHUP": syscall.SIGHUP,
+}
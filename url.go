@@ -0,0 +1,94 @@
+package hime
+
+import (
+	"net/http"
+	"strings"
+)
+
+// BaseURL sets canonical base url (e.g. https://example.com)
+// that used to generate absolute url instead of request's scheme and host
+func (app *App) BaseURL(u string) *App {
+	app.baseURL = strings.TrimSuffix(u, "/")
+	return app
+}
+
+// TrustProxy trusts X-Forwarded-Proto, X-Forwarded-Host and Forwarded headers
+// when generate absolute url from request
+func (app *App) TrustProxy(enable bool) *App {
+	app.trustProxy = enable
+	return app
+}
+
+// RouteURL gets absolute url from route name using app's base url,
+// returns only path if base url is not set
+func (app *App) RouteURL(name string, params ...interface{}) string {
+	return app.baseURL + app.Route(name, params...)
+}
+
+// urlFunc binds url template func to ctx
+func urlFunc(ctx *Context) interface{} {
+	return ctx.RouteURL
+}
+
+// BaseURL returns scheme and host for current request,
+// uses app's base url if set
+func (ctx *Context) BaseURL() string {
+	if ctx.app.baseURL != "" {
+		return ctx.app.baseURL
+	}
+
+	scheme, host := "http", ctx.Host
+	if ctx.TLS != nil {
+		scheme = "https"
+	}
+	if ctx.app.trustProxy {
+		if p, h := parseForwarded(ctx.Request); p != "" || h != "" {
+			if p != "" {
+				scheme = p
+			}
+			if h != "" {
+				host = h
+			}
+		} else {
+			if p := firstHeaderValue(ctx.Header.Get("X-Forwarded-Proto")); p != "" {
+				scheme = p
+			}
+			if h := firstHeaderValue(ctx.Header.Get("X-Forwarded-Host")); h != "" {
+				host = h
+			}
+		}
+	}
+	return strings.ToLower(scheme) + "://" + host
+}
+
+// RouteURL gets absolute url from route name
+func (ctx *Context) RouteURL(name string, params ...interface{}) string {
+	return ctx.BaseURL() + ctx.app.Route(name, params...)
+}
+
+func firstHeaderValue(s string) string {
+	if i := strings.IndexByte(s, ','); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+// parseForwarded parses proto and host from first element of Forwarded header (RFC 7239)
+func parseForwarded(r *http.Request) (proto, host string) {
+	v := firstHeaderValue(r.Header.Get("Forwarded"))
+	for _, pair := range strings.Split(v, ";") {
+		i := strings.IndexByte(pair, '=')
+		if i < 0 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(pair[:i]))
+		value := strings.Trim(strings.TrimSpace(pair[i+1:]), `"`)
+		switch key {
+		case "proto":
+			proto = value
+		case "host":
+			host = value
+		}
+	}
+	return
+}
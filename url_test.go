@@ -0,0 +1,94 @@
+package hime
+
+import (
+	"bytes"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURL(t *testing.T) {
+	t.Parallel()
+
+	newApp := func() *App {
+		return New().Routes(Routes{"user": "/users/{id}"})
+	}
+
+	t.Run("App RouteURL", func(t *testing.T) {
+		app := newApp()
+		assert.Equal(t, "/users/1", app.RouteURL("user", 1))
+
+		app.BaseURL("https://example.com/")
+		assert.Equal(t, "https://example.com/users/1?a=b", app.RouteURL("user", 1, &Param{"a", "b"}))
+	})
+
+	cases := []struct {
+		Name       string
+		BaseURL    string
+		TrustProxy bool
+		TLS        bool
+		Header     http.Header
+		Output     string
+	}{
+		{"request", "", false, false, nil, "http://example.com/users/1"},
+		{"tls", "", false, true, nil, "https://example.com/users/1"},
+		{"untrusted proxy", "", false, false, http.Header{"X-Forwarded-Proto": {"https"}}, "http://example.com/users/1"},
+		{"x-forwarded", "", true, false, http.Header{"X-Forwarded-Proto": {"https, http"}, "X-Forwarded-Host": {"proxy.com"}}, "https://proxy.com/users/1"},
+		{"forwarded", "", true, false, http.Header{"Forwarded": {`for=1.2.3.4;proto=HTTPS;host="proxy.com", for=5.6.7.8`}}, "https://proxy.com/users/1"},
+		{"forwarded proto only", "", true, true, http.Header{"Forwarded": {`proto=http`}}, "http://example.com/users/1"},
+		{"base url", "https://canonical.com", true, false, http.Header{"X-Forwarded-Host": {"proxy.com"}}, "https://canonical.com/users/1"},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run("Context RouteURL "+c.Name, func(t *testing.T) {
+			app := newApp().BaseURL(c.BaseURL).TrustProxy(c.TrustProxy)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+			if c.TLS {
+				r.TLS = &tls.ConnectionState{}
+			}
+			for k, v := range c.Header {
+				r.Header[k] = v
+			}
+
+			assert.Equal(t, c.Output, NewAppContext(app, w, r).RouteURL("user", 1))
+		})
+	}
+
+	t.Run("url template func", func(t *testing.T) {
+		app := newApp()
+		app.Template().Parse("t", `<a href="{{url "user" 1}}">user</a>`)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+		assert.NoError(t, NewAppContext(app, w, r).View("t", nil))
+		assert.Equal(t, `<a href="https://example.com/users/1">user</a>`, w.Body.String())
+
+		var b bytes.Buffer
+		app.BaseURL("https://canonical.com")
+		assert.NoError(t, app.RenderView(&b, "t", nil))
+		assert.Equal(t, `<a href="https://canonical.com/users/1">user</a>`, b.String())
+	})
+
+	t.Run("template without context funcs is not pooled", func(t *testing.T) {
+		app := newApp()
+		app.Template().Parse("t", `{{route "user" 1}}`).Parse("u", `{{define "x"}}{{url "user" 1}}{{end}}{{template "x"}}`)
+
+		assert.Nil(t, app.template["t"].pool)
+		assert.NotNil(t, app.template["u"].pool)
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		app := New().ParseConfig([]byte(`
+baseURL: https://example.com/
+server:
+  trustProxy: true`))
+		assert.Equal(t, "https://example.com", app.baseURL)
+		assert.True(t, app.trustProxy)
+	})
+}
@@ -0,0 +1,245 @@
+package hime
+
+import (
+	"fmt"
+	"html/template"
+	"sort"
+	texttemplate "text/template"
+	"text/template/parse"
+)
+
+// VerifyOnStart verifies templates before app.ListenAndServe start server
+func (app *App) VerifyOnStart(enable bool) *App {
+	app.verifyOnStart = enable
+	return app
+}
+
+// Verify walks every parsed template, component and text template,
+// reports unknown route names, undefined templates and missing components
+func (app *App) Verify() error {
+	names := make([]string, 0, len(app.template))
+	for name := range app.template {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		t := app.template[name]
+		v := verifier{
+			app:        app,
+			kind:       "template",
+			name:       name,
+			lookup:     htmlLookup(t.Template),
+			components: t.components,
+		}
+		errs = append(errs, v.verify(htmlTrees(t.Template))...)
+	}
+
+	// components share between templates from same Template
+	seen := make(map[*template.Template]bool)
+	for _, name := range names {
+		t := app.template[name]
+
+		cs := make([]string, 0, len(t.components))
+		for c := range t.components {
+			cs = append(cs, c)
+		}
+		sort.Strings(cs)
+
+		for _, c := range cs {
+			x := t.components[c]
+			if seen[x] {
+				continue
+			}
+			seen[x] = true
+
+			v := verifier{
+				app:        app,
+				kind:       "component",
+				name:       c,
+				lookup:     htmlLookup(x),
+				components: t.components,
+			}
+			errs = append(errs, v.verify(htmlTrees(x))...)
+		}
+	}
+
+	textNames := make([]string, 0, len(app.textTemplate))
+	for name := range app.textTemplate {
+		textNames = append(textNames, name)
+	}
+	sort.Strings(textNames)
+
+	for _, name := range textNames {
+		t := app.textTemplate[name]
+		v := verifier{
+			app:  app,
+			kind: "text template",
+			name: name,
+			lookup: func(name string) bool {
+				x := t.Lookup(name)
+				return x != nil && x.Tree != nil
+			},
+		}
+		errs = append(errs, v.verify(textTrees(t))...)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ErrVerify{Errors: errs}
+}
+
+func htmlLookup(t *template.Template) func(name string) bool {
+	return func(name string) bool {
+		x := t.Lookup(name)
+		return x != nil && x.Tree != nil
+	}
+}
+
+func htmlTrees(t *template.Template) []*parse.Tree {
+	var trees []*parse.Tree
+	for _, x := range t.Templates() {
+		trees = append(trees, x.Tree)
+	}
+	return trees
+}
+
+func textTrees(t *texttemplate.Template) []*parse.Tree {
+	var trees []*parse.Tree
+	for _, x := range t.Templates() {
+		trees = append(trees, x.Tree)
+	}
+	return trees
+}
+
+type verifier struct {
+	app        *App
+	kind       string
+	name       string
+	lookup     func(name string) bool
+	components map[string]*template.Template
+	errs       []error
+}
+
+func (v *verifier) errorf(format string, a ...interface{}) {
+	v.errs = append(v.errs, fmt.Errorf("%s '%s'; "+format, append([]interface{}{v.kind, v.name}, a...)...))
+}
+
+// verify walks every tree in the set
+func (v *verifier) verify(trees []*parse.Tree) []error {
+	for _, x := range trees {
+		if x == nil || x.Root == nil {
+			continue
+		}
+		walkTree(x.Root, v.visit)
+	}
+	return v.errs
+}
+
+func (v *verifier) visit(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.TemplateNode:
+		if !v.lookup(n.Name) {
+			v.errorf("%v", newErrTemplateNotFound(n.Name))
+		}
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for i, cmd := range n.Cmds {
+			if len(cmd.Args) >= 2 {
+				// {{route "name"}}
+				v.verifyCall(cmd.Args[0], cmd.Args[1])
+			} else if i > 0 && len(cmd.Args) == 1 && len(n.Cmds[i-1].Args) == 1 {
+				// {{"name" | route}}
+				v.verifyCall(cmd.Args[0], n.Cmds[i-1].Args[0])
+			}
+		}
+	}
+}
+
+// verifyCall verifies func call that first argument is name
+func (v *verifier) verifyCall(fnNode, nameNode parse.Node) {
+	fn, ok := fnNode.(*parse.IdentifierNode)
+	if !ok {
+		return
+	}
+	s, ok := nameNode.(*parse.StringNode)
+	if !ok {
+		return
+	}
+	switch fn.Ident {
+	case "route", "url":
+		if _, ok := v.app.routes[s.Text]; !ok {
+			v.errorf("%v", newErrRouteNotFound(s.Text))
+		}
+	case "component":
+		if _, ok := v.components[s.Text]; !ok {
+			v.errorf("component '%s' not found", s.Text)
+		}
+	}
+}
+
+// walkTree calls fn for every node in node's tree
+func walkTree(node parse.Node, fn func(parse.Node)) {
+	fn(node)
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, x := range n.Nodes {
+			walkTree(x, fn)
+		}
+	case *parse.ActionNode:
+		walkTree(n.Pipe, fn)
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.TemplateNode:
+		walkTree(n.Pipe, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkTree(cmd, fn)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkTree(arg, fn)
+		}
+	case *parse.ChainNode:
+		walkTree(n.Node, fn)
+	}
+}
+
+func walkBranch(n *parse.BranchNode, fn func(parse.Node)) {
+	walkTree(n.Pipe, fn)
+	walkTree(n.List, fn)
+	walkTree(n.ElseList, fn)
+}
+
+// usesFuncs reports whether any template in t's set calls any of given funcs
+func usesFuncs(t *template.Template, names map[string]struct{}) bool {
+	found := false
+	for _, x := range t.Templates() {
+		if x.Tree == nil || x.Tree.Root == nil {
+			continue
+		}
+		walkTree(x.Tree.Root, func(node parse.Node) {
+			if n, ok := node.(*parse.IdentifierNode); ok {
+				if _, ok := names[n.Ident]; ok {
+					found = true
+				}
+			}
+		})
+	}
+	return found
+}
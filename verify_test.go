@@ -0,0 +1,106 @@
+package hime
+
+import (
+	"html/template"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid", func(t *testing.T) {
+		app := New()
+		app.Routes(Routes{"index": "/"})
+		tp := app.Template()
+		tp.Component(template.Must(template.New("c").Parse(`c`)))
+		tp.Parse("t", `{{define "x"}}x{{end}}{{if true}}{{route "index" (param "id" 1)}}{{end}}{{template "x"}}{{component "c"}}{{len "a"}}`)
+
+		assert.NoError(t, app.Verify())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		assert.NoError(t, New().Verify())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		app := New()
+		app.Routes(Routes{"index": "/"})
+		tp := app.Template()
+		tp.Parse("t1", `{{range .}}{{route "missing"}}{{else}}{{template "y"}}{{end}}`)
+		tp.Parse("t2", `{{with .}}{{component "c"}}{{end}}{{route .Name}}`)
+
+		err := app.Verify()
+		if assert.Error(t, err) {
+			if assert.IsType(t, &ErrVerify{}, err) {
+				assert.Len(t, err.(*ErrVerify).Errors, 3)
+			}
+			assert.Contains(t, err.Error(), "template 't1'; hime: route 'missing' not found")
+			assert.Contains(t, err.Error(), "template 't1'; hime: template 'y' not found")
+			assert.Contains(t, err.Error(), "template 't2'; component 'c' not found")
+		}
+	})
+
+	t.Run("Components", func(t *testing.T) {
+		app := New()
+		tp := app.Template()
+		tp.Dir("testdata/template")
+		tp.ComponentDir("comp")
+		tp.Parse("t", `{{component "button"}}`)
+
+		err := app.Verify()
+		if assert.Error(t, err) {
+			assert.EqualError(t, err, "hime: verify failed; component 'button'; hime: route 'index' not found")
+		}
+
+		app.Routes(Routes{"index": "/"})
+		assert.NoError(t, app.Verify())
+	})
+
+	t.Run("Url and pipeline", func(t *testing.T) {
+		app := New()
+		app.Routes(Routes{"index": "/"})
+		tp := app.Template()
+		tp.Parse("t", `{{url "index"}}{{"index" | route}}{{"index" | url}}{{route "index" | printf "%s"}}`)
+		assert.NoError(t, app.Verify())
+
+		tp.Parse("t2", `{{url "a"}}{{"b" | route}}{{"c" | url}}{{"d" | component}}`)
+		err := app.Verify()
+		if assert.IsType(t, &ErrVerify{}, err) {
+			assert.Len(t, err.(*ErrVerify).Errors, 4)
+			assert.Contains(t, err.Error(), "template 't2'; hime: route 'a' not found")
+			assert.Contains(t, err.Error(), "template 't2'; hime: route 'b' not found")
+			assert.Contains(t, err.Error(), "template 't2'; hime: route 'c' not found")
+			assert.Contains(t, err.Error(), "template 't2'; component 'd' not found")
+		}
+	})
+
+	t.Run("Text template", func(t *testing.T) {
+		app := New()
+		app.Routes(Routes{"index": "/"})
+		app.TextTemplate().Parse("m", `{{define "x"}}x{{end}}{{route "index"}}{{template "x"}}`)
+		assert.NoError(t, app.Verify())
+
+		app.TextTemplate().Parse("m2", `{{route "missing"}}{{template "y"}}`)
+		err := app.Verify()
+		if assert.IsType(t, &ErrVerify{}, err) {
+			assert.Len(t, err.(*ErrVerify).Errors, 2)
+			assert.Contains(t, err.Error(), "text template 'm2'; hime: route 'missing' not found")
+			assert.Contains(t, err.Error(), "text template 'm2'; hime: template 'y' not found")
+		}
+	})
+
+	t.Run("VerifyOnStart", func(t *testing.T) {
+		app := New()
+		app.Template().Parse("t", `{{route "missing"}}`)
+		app.VerifyOnStart(true)
+
+		assert.IsType(t, &ErrVerify{}, app.ListenAndServe())
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		app := New().ParseConfig([]byte(`verify: true`))
+		assert.True(t, app.verifyOnStart)
+	})
+}
@@ -0,0 +1,173 @@
+package hime
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/css"
+	"github.com/tdewolff/minify/v2/html"
+	"github.com/tdewolff/minify/v2/js"
+)
+
+// ViewEngine is the engine that renders views for ctx.View
+type ViewEngine interface {
+	// HasView reports whether engine has view with given name
+	HasView(name string) bool
+
+	// ExecuteView renders view into w,
+	// ctx is nil when render outside request
+	ExecuteView(ctx *Context, w io.Writer, name string, data interface{}) error
+}
+
+// ViewEngine registers view engines,
+// ctx.View looks up view from app's templates then registered engines in registration order
+func (app *App) ViewEngine(engines ...ViewEngine) *App {
+	app.viewEngines = append(app.viewEngines, engines...)
+	return app
+}
+
+// templateEngine is the view engine for app's html templates
+type templateEngine struct {
+	app *App
+}
+
+func (e templateEngine) HasView(name string) bool {
+	_, ok := e.app.template[name]
+	return ok
+}
+
+func (e templateEngine) ExecuteView(ctx *Context, w io.Writer, name string, data interface{}) error {
+	return e.app.template[name].ExecuteContext(ctx, w, data)
+}
+
+func (e templateEngine) executeViewStats(ctx *Context, w *bytes.Buffer, name string, data interface{}, st *RenderStats) error {
+	return e.app.template[name].executeContext(ctx, w, data, st)
+}
+
+// lookupView returns view engine that has given view name
+func (app *App) lookupView(name string) ViewEngine {
+	if e := (templateEngine{app}); e.HasView(name) {
+		return e
+	}
+	for _, e := range app.viewEngines {
+		if e.HasView(name) {
+			return e
+		}
+	}
+	return nil
+}
+
+type minifyViewEngine struct {
+	ViewEngine
+	m *minify.M
+}
+
+// MinifyViewEngine wraps view engine to minify rendered html, css and js
+func MinifyViewEngine(e ViewEngine) ViewEngine {
+	return &minifyViewEngine{e, newViewMinifier()}
+}
+
+// MinifyView enables minify for views that registered view engines render,
+// app's templates minify by template's minify config
+func (app *App) MinifyView(enable bool) *App {
+	app.viewMinifier = nil
+	if enable {
+		app.viewMinifier = newViewMinifier()
+	}
+	return app
+}
+
+func newViewMinifier() *minify.M {
+	m := minify.New()
+	m.Add("text/html", html.DefaultMinifier)
+	m.Add("text/css", &css.Minifier{})
+	m.Add("application/javascript", js.DefaultMinifier)
+	return m
+}
+
+// getViewMinifier returns minifier for view that e renders,
+// returns nil if e already minifies
+func (app *App) getViewMinifier(e ViewEngine) *minify.M {
+	switch e.(type) {
+	case templateEngine, *minifyViewEngine:
+		return nil
+	}
+	return app.viewMinifier
+}
+
+// minifyBuffer minifies html in buf
+func minifyBuffer(m *minify.M, buf *bytes.Buffer) error {
+	x := getBytes()
+	defer putBytes(x)
+
+	err := m.Minify("text/html", x, buf)
+	if err != nil {
+		return err
+	}
+
+	buf.Reset()
+	_, err = x.WriteTo(buf)
+	return err
+}
+
+func (e *minifyViewEngine) ExecuteView(ctx *Context, w io.Writer, name string, data interface{}) error {
+	buf := getBytes()
+	defer putBytes(buf)
+
+	err := e.ViewEngine.ExecuteView(ctx, buf, name, data)
+	if err != nil {
+		return err
+	}
+
+	return e.m.Minify("text/html", w, buf)
+}
+
+// ViewDataFunc wraps handler's data before ctx.View renders template
+type ViewDataFunc func(ctx *Context, data interface{}) interface{}
+
+// ViewData registers view data funcs,
+// funcs are called in registration order when ctx.View renders template
+func (app *App) ViewData(fns ...ViewDataFunc) *App {
+	app.viewDataFuncs = append(app.viewDataFuncs, fns...)
+	return app
+}
+
+func (app *App) viewData(ctx *Context, data interface{}) interface{} {
+	for _, fn := range app.viewDataFuncs {
+		data = fn(ctx, data)
+	}
+	return data
+}
+
+// ContextData is the view data that contains request's context and handler's data
+type ContextData struct {
+	Ctx  *Context
+	Data interface{}
+}
+
+// WithContextData is the ViewDataFunc that wraps handler's data into ContextData,
+// template can access context as .Ctx and handler's data as .Data
+func WithContextData(ctx *Context, data interface{}) interface{} {
+	return &ContextData{Ctx: ctx, Data: data}
+}
+
+func cloneViewEngines(xs []ViewEngine) []ViewEngine {
+	if xs == nil {
+		return nil
+	}
+
+	rs := make([]ViewEngine, len(xs))
+	copy(rs, xs)
+	return rs
+}
+
+func cloneViewDataFuncs(xs []ViewDataFunc) []ViewDataFunc {
+	if xs == nil {
+		return nil
+	}
+
+	rs := make([]ViewDataFunc, len(xs))
+	copy(rs, xs)
+	return rs
+}